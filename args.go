@@ -0,0 +1,224 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Suffix marking the last positional argument as variadic.
+const variadicSuffix = "..."
+
+// Args declares the positional arguments of the sub-command. Parse
+// fails with the sub-command usage unless each of them is provided.
+// If a name ends with "...", e.g. "files...", it is variadic and
+// accepts one or more arguments, e.g. "src..." for "cp src... dst".
+// Only one of the names can be variadic.
+func Args(names ...string) Option {
+	return func(cont *cmdCont) {
+		cont.args = names
+	}
+}
+
+// ArgHelp describes the declared positional argument name, e.g.
+// "files" for "files...". The descriptions are listed in the
+// sub-command usage like the flags.
+func ArgHelp(name, description string) Option {
+	return func(cont *cmdCont) {
+		if cont.argsHelp == nil {
+			cont.argsHelp = make(map[string]string)
+		}
+		cont.argsHelp[strings.TrimSuffix(name, variadicSuffix)] = description
+	}
+}
+
+// Prints the descriptions of the declared positionals to w.
+func (cont *cmdCont) printArgsHelp(w io.Writer) {
+	if len(cont.argsHelp) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", UsageStrings.Arguments)
+	for _, name := range cont.args {
+		if desc, ok := cont.argsHelp[strings.TrimSuffix(name, variadicSuffix)]; ok {
+			desc = strings.ReplaceAll(desc, "\n", "\n    \t")
+			fmt.Fprintf(w, "  <%s>\n    \t%s\n", name, desc)
+		}
+	}
+}
+
+// BindArg binds the declared positional argument name to the flag
+// flagName of the sub-command, e.g. a duration flag for "timeout" in
+// "wait <timeout>". The argument is parsed by the flag before Run,
+// each value of a variadic one is set in turn, so a flag accepting
+// multiple values can collect them. A value the flag rejects fails
+// the parsing with the sub-command usage. The flag can be hidden
+// with HideFlag.
+func BindArg(name, flagName string) Option {
+	return func(cont *cmdCont) {
+		if cont.argFlags == nil {
+			cont.argFlags = make(map[string]string)
+		}
+		cont.argFlags[strings.TrimSuffix(name, variadicSuffix)] = flagName
+	}
+}
+
+// Sets the arguments bound to flags of fs, returns the values the
+// flags reject. The arguments must match the declared positionals.
+func (cont *cmdCont) bindArgs(fs *flag.FlagSet, args []string) Errors {
+	if len(cont.argFlags) == 0 && len(cont.argVars) == 0 {
+		return nil
+	}
+	var errs Errors
+	cont.eachArg(args, func(name string, values []string) {
+		var set func(value string) error
+		if v, ok := cont.argVars[name]; ok {
+			set = v.Set
+		}
+		if flagName, ok := cont.argFlags[name]; ok {
+			if fs.Lookup(flagName) == nil {
+				errs = append(errs, fmt.Errorf("argument <%s> is bound to undefined flag -%s", name, flagName))
+				return
+			}
+			set = func(value string) error {
+				return fs.Set(flagName, value)
+			}
+		}
+		if set == nil {
+			return
+		}
+		for _, value := range values {
+			if err := set(value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value %q for argument <%s>: %v", value, name, err))
+			}
+		}
+	})
+	return errs
+}
+
+// Calls fn with the name of each declared positional, without the
+// variadic suffix, and the arguments it matches. The arguments must
+// match the declared positionals.
+func (cont *cmdCont) eachArg(args []string, fn func(name string, values []string)) {
+	extra := len(args) - len(cont.args)
+	for i, j := 0, 0; i < len(cont.args); i++ {
+		n := 1
+		if i == cont.variadicIndex() {
+			n += extra
+		}
+		fn(strings.TrimSuffix(cont.args[i], variadicSuffix), args[j:j+n])
+		j += n
+	}
+}
+
+// arity bounds the number of positional arguments.
+type arity struct {
+	min, max int
+}
+
+// RangeArgs bounds the number of positional arguments of the
+// sub-command, max < 0 is unbounded. Parse fails with the sub-command
+// usage if it's out of the bounds. It's checked in addition to Args.
+func RangeArgs(min, max int) Option {
+	return func(cont *cmdCont) {
+		cont.arity = &arity{min: min, max: max}
+	}
+}
+
+// MinArgs requires at least n positional arguments.
+func MinArgs(n int) Option {
+	return RangeArgs(n, -1)
+}
+
+// MaxArgs accepts at most n positional arguments.
+func MaxArgs(n int) Option {
+	return RangeArgs(0, n)
+}
+
+// ExactArgs requires exactly n positional arguments.
+func ExactArgs(n int) Option {
+	return RangeArgs(n, n)
+}
+
+// Returns the index of the variadic positional, -1 if there is none.
+func (cont *cmdCont) variadicIndex() int {
+	for i, name := range cont.args {
+		if strings.HasSuffix(name, variadicSuffix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Reports whether the arguments match the declared positionals.
+// Sub-commands without declared positionals accept any arguments.
+func (cont *cmdCont) validArgs(args []string) bool {
+	return cont.checkArgs(args) == nil
+}
+
+// Returns an error if the arguments don't match the declared
+// positionals or are out of the bounds of the sub-command.
+func (cont *cmdCont) checkArgs(args []string) error {
+	if cont.args != nil {
+		if n := len(cont.args); len(args) < n || (len(args) > n && cont.variadicIndex() < 0) {
+			return fmt.Errorf("%s expects arguments%s", cont.name, cont.argsUsage())
+		}
+	}
+	if a := cont.arity; a != nil && (len(args) < a.min || (a.max >= 0 && len(args) > a.max)) {
+		return fmt.Errorf("%s expects %s, found %d", cont.name, a, len(args))
+	}
+	return nil
+}
+
+// Describes the bounds, e.g. "1 to 3 arguments".
+func (a *arity) String() string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 argument"
+		}
+		return fmt.Sprintf("%d arguments", n)
+	}
+	switch {
+	case a.min == a.max:
+		return plural(a.min)
+	case a.max < 0:
+		return "at least " + plural(a.min)
+	case a.min == 0:
+		return "at most " + plural(a.max)
+	}
+	return fmt.Sprintf("%d to %s", a.min, plural(a.max))
+}
+
+// Returns the declared positionals as they appear in the usage,
+// e.g. " <src> <files...>", or the bounds if they aren't declared,
+// e.g. " <arg> [<arg>...]".
+func (cont *cmdCont) argsUsage() string {
+	var usage string
+	for _, name := range cont.args {
+		usage += " <" + name + ">"
+	}
+	if a := cont.arity; cont.args == nil && a != nil {
+		usage += strings.Repeat(" <arg>", a.min)
+		switch {
+		case a.max == a.min+1:
+			usage += " [<arg>]"
+		case a.max < 0 || a.max > a.min:
+			usage += " [<arg>...]"
+		}
+	}
+	return usage
+}
@@ -0,0 +1,171 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests the number of arguments accepted by declared positionals.
+func TestValidArgs(t *testing.T) {
+	tests := []struct {
+		names []string
+		args  []string
+		valid bool
+	}{
+		{nil, []string{"a", "b"}, true},
+		{[]string{"src", "dst"}, []string{"a", "b"}, true},
+		{[]string{"src", "dst"}, []string{"a"}, false},
+		{[]string{"src", "dst"}, []string{"a", "b", "c"}, false},
+		{[]string{"dst", "files..."}, []string{"a"}, false},
+		{[]string{"dst", "files..."}, []string{"a", "b"}, true},
+		{[]string{"dst", "files..."}, []string{"a", "b", "c", "d"}, true},
+		{[]string{"src...", "dst"}, []string{"a"}, false},
+		{[]string{"src...", "dst"}, []string{"a", "b", "c"}, true},
+	}
+	for _, tt := range tests {
+		cont := &cmdCont{args: tt.names}
+		if valid := cont.validArgs(tt.args); valid != tt.valid {
+			t.Errorf("args %v for %v: expected valid=%v, found %v", tt.args, tt.names, tt.valid, valid)
+		}
+	}
+}
+
+// Tests if the declared positionals are rendered in the usage.
+func TestArgsUsage(t *testing.T) {
+	resetForTesting("command1", "-h")
+
+	On("command1", "", &testCmd1{}, []string{}, Args("dst", "files..."))
+	Parse()
+	out := captureStderr(Run)
+	if !strings.Contains(out, "cmd command1 <dst> <files...>:") {
+		t.Errorf("usage was expected to contain the positionals, found %q", out)
+	}
+}
+
+// Tests if every trailing position of a variadic is completed.
+func TestCompleteVariadic(t *testing.T) {
+	resetForTesting()
+
+	On("command1", "", &testCmd1{}, []string{}, Args("dst", "files..."))
+	if found := defaultSet.complete([]string{"command1", "a", "b", "c", "args_test.g"}); len(found) != 1 || found[0] != "args_test.go" {
+		t.Errorf("expected [args_test.go], found %v", found)
+	}
+	On("command2", "", &testCmd1{}, []string{}, Args("dst"))
+	if found := defaultSet.complete([]string{"command2", "a", "args_test.g"}); len(found) != 0 {
+		t.Errorf("expected no candidates, found %v", found)
+	}
+}
+
+// Tests if the number of arguments is bounded.
+func TestArgsArity(t *testing.T) {
+	tests := []struct {
+		opt  Option
+		args []string
+		err  string
+	}{
+		{ExactArgs(2), []string{"a", "b"}, ""},
+		{ExactArgs(1), []string{"a", "b"}, "command1 expects 1 argument, found 2"},
+		{MinArgs(2), []string{"a"}, "command1 expects at least 2 arguments, found 1"},
+		{MaxArgs(1), nil, ""},
+		{MaxArgs(1), []string{"a", "b"}, "command1 expects at most 1 argument, found 2"},
+		{RangeArgs(1, 3), []string{"a", "b", "c", "d"}, "command1 expects 1 to 3 arguments, found 4"},
+	}
+	for _, tt := range tests {
+		cont := &cmdCont{name: "command1"}
+		tt.opt(cont)
+		err := cont.checkArgs(tt.args)
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("args %v: expected error %q, found %v", tt.args, tt.err, err)
+		}
+	}
+	for _, tt := range []struct {
+		opt  Option
+		want string
+	}{
+		{ExactArgs(2), " <arg> <arg>"},
+		{MinArgs(1), " <arg> [<arg>...]"},
+		{MaxArgs(1), " [<arg>]"},
+		{RangeArgs(1, 3), " <arg> [<arg>...]"},
+	} {
+		cont := &cmdCont{}
+		tt.opt(cont)
+		if found := cont.argsUsage(); found != tt.want {
+			t.Errorf("expected usage %q, found %q", tt.want, found)
+		}
+	}
+}
+
+// Tests if the positionals are described in the usage.
+func TestArgHelp(t *testing.T) {
+	resetForTesting()
+
+	On("command1", "", &testCmd1{}, []string{}, Args("dst", "files..."), ArgHelp("dst", "directory to copy to"), ArgHelp("files...", "files to copy"))
+	usage, _ := SubcommandUsageString("command1")
+	want := "\narguments:\n  <dst>\n    \tdirectory to copy to\n  <files...>\n    \tfiles to copy\n"
+	if !strings.Contains(usage, want) {
+		t.Errorf("expected the usage to contain %q, found %q", want, usage)
+	}
+}
+
+// Tests if positionals are parsed by the flags they are bound to.
+func TestBindArg(t *testing.T) {
+	var timeout *time.Duration
+	var ports []int
+	cmd := &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		timeout = fs.Duration("timeout", 0, "")
+		ports = nil
+		fs.Func("port", "", func(s string) error {
+			n, err := strconv.Atoi(s)
+			ports = append(ports, n)
+			return err
+		})
+	}}
+	opts := []Option{Args("timeout", "ports..."), BindArg("timeout", "timeout"), BindArg("ports", "port")}
+
+	resetForTesting("wait", "30s", "80", "443")
+	On("wait", "", cmd, nil, opts...)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if *timeout != 30*time.Second || !reflect.DeepEqual(ports, []int{80, 443}) {
+		t.Errorf("unexpected values %v, %v", *timeout, ports)
+	}
+
+	resetForTesting("wait", "soon", "80")
+	On("wait", "", cmd, nil, opts...)
+	err := ParseErr()
+	if err == nil || !strings.Contains(err.Error(), `invalid value "soon" for argument <timeout>`) {
+		t.Errorf("expected a conversion error, found %v", err)
+	}
+}
+
+// Tests if a sub-command missing a required positional isn't run.
+func TestMissingArgs(t *testing.T) {
+	cmd := &testCmd1{}
+	s := NewWithErrorHandling("tool", flag.ContinueOnError)
+	s.On("deploy", "", cmd, nil, Args("target"))
+	if err := s.ParseAndRunE([]string{"deploy"}); err == nil || !strings.Contains(err.Error(), "deploy expects arguments <target>") {
+		t.Errorf("expected the missing target to be reported, found %v", err)
+	}
+	if cmd.run {
+		t.Error("deploy was not expected to run")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ArgValidator checks the value of a positional argument, returns an
+// error describing why it's rejected.
+type ArgValidator func(value string) error
+
+// ValidateArg checks the values of the declared positional argument
+// name with the validators during Parse, e.g.
+//
+//	command.On("scale", "scales a service", cmd, nil,
+//		command.Args("service", "replicas"),
+//		command.ValidateArg("replicas", command.IntRange(0, 100)))
+//
+// Every value of a variadic argument is checked. All of the rejected
+// values are reported at once with the other problems found by Parse.
+func ValidateArg(name string, validators ...ArgValidator) Option {
+	return func(cont *cmdCont) {
+		if cont.argValidators == nil {
+			cont.argValidators = make(map[string][]ArgValidator)
+		}
+		name = strings.TrimSuffix(name, variadicSuffix)
+		cont.argValidators[name] = append(cont.argValidators[name], validators...)
+	}
+}
+
+// Returns the values of the arguments rejected by their validators.
+// The arguments must match the declared positionals.
+func (cont *cmdCont) validateArgs(args []string) Errors {
+	if len(cont.argValidators) == 0 {
+		return nil
+	}
+	var errs Errors
+	cont.eachArg(args, func(name string, values []string) {
+		for _, value := range values {
+			for _, validate := range cont.argValidators[name] {
+				if err := validate(value); err != nil {
+					errs = append(errs, fmt.Errorf("invalid value %q for argument <%s>: %v", value, name, err))
+					break
+				}
+			}
+		}
+	})
+	return errs
+}
+
+// IntRange accepts the integers from min to max, inclusive.
+func IntRange(min, max int) ArgValidator {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		if n < min || n > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+// MatchRegexp accepts the values matching the regular expression
+// pattern. It panics if the pattern doesn't compile.
+func MatchRegexp(pattern string) ArgValidator {
+	re := regexp.MustCompile(pattern)
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %s", pattern)
+		}
+		return nil
+	}
+}
+
+// OneOf accepts the values given.
+func OneOf(values ...string) ArgValidator {
+	return func(value string) error {
+		if !contains(values, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
+
+// ExistingFile accepts the paths of existing files which aren't
+// directories.
+func ExistingFile() ArgValidator {
+	return func(value string) error {
+		fi, err := os.Stat(value)
+		if os.IsNotExist(err) {
+			return errors.New("no such file")
+		}
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return errors.New("is a directory")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Tests if the validators accept and reject values.
+func TestArgValidators(t *testing.T) {
+	f, err := ioutil.TempFile("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	for _, tt := range []struct {
+		validate ArgValidator
+		value    string
+		valid    bool
+	}{
+		{IntRange(1, 3), "3", true},
+		{IntRange(1, 3), "4", false},
+		{IntRange(1, 3), "x", false},
+		{MatchRegexp(`^v\d+$`), "v2", true},
+		{MatchRegexp(`^v\d+$`), "2", false},
+		{OneOf("a", "b"), "b", true},
+		{OneOf("a", "b"), "c", false},
+		{ExistingFile(), f.Name(), true},
+		{ExistingFile(), os.TempDir(), false},
+		{ExistingFile(), f.Name() + ".missing", false},
+	} {
+		if err := tt.validate(tt.value); (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid to be %v, found %v", tt.value, tt.valid, err)
+		}
+	}
+}
+
+// Tests if all of the rejected arguments are reported by Parse.
+func TestValidateArg(t *testing.T) {
+	resetForTesting("command1", "x", "5", "a", "c", "d")
+
+	On("command1", "", &testCmd1{}, nil,
+		Args("service", "replicas", "zones..."),
+		ValidateArg("replicas", IntRange(0, 3)),
+		ValidateArg("zones...", OneOf("a", "b")))
+	err := ParseErr()
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected 3 problems, found %v", err)
+	}
+	for _, want := range []string{`"5" for argument <replicas>: must be between 0 and 3`, `"c" for argument <zones>`, `"d" for argument <zones>`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+}
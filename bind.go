@@ -0,0 +1,118 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// BindFlags defines a flag on fs for each field of the struct v
+// points to with a `flag` tag, backed by the field, e.g.
+//
+//	type options struct {
+//		Port int           `flag:"port" default:"8080" usage:"listen port"`
+//		Host string        `flag:"host" required:"true"`
+//		Tags []string      `flag:"tag" sep:","`
+//		Wait time.Duration `flag:"wait" alias:"w"`
+//	}
+//
+// Fields of type string, bool, int, int64, uint, uint64, float64,
+// time.Duration, []string and []int, and the ones implementing
+// flag.Value through a pointer are supported. A field without a
+// default tag defaults to its current value. The values set to a
+// slice replace its default rather than being appended to it. The
+// fields of embedded structs are bound as well. It panics if v is not
+// a pointer to a struct, or a field or a default is invalid.
+func BindFlags(fs *flag.FlagSet, v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("command: BindFlags of %T, a pointer to a struct was expected", v))
+	}
+	bindFields(fs, rv.Elem())
+}
+
+// Binds the tagged fields of the struct value rv to flags of fs.
+func bindFields(fs *flag.FlagSet, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field, fv := rt.Field(i), rv.Field(i)
+		name, ok := field.Tag.Lookup("flag")
+		if !ok {
+			if field.Anonymous && fv.Kind() == reflect.Struct {
+				bindFields(fs, fv)
+			}
+			continue
+		}
+		if name == "-" || !fv.CanSet() {
+			continue
+		}
+		usage := field.Tag.Get("usage")
+		if err := bindField(fs, fv, name, usage, field.Tag.Get("sep")); err != nil {
+			panic(fmt.Sprintf("command: field %s: %v", field.Name, err))
+		}
+		f := fs.Lookup(name)
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := f.Value.Set(def); err != nil {
+				panic(fmt.Sprintf("command: field %s: invalid default %q: %v", field.Name, def, err))
+			}
+			f.DefValue = f.Value.String()
+			markSliceDefault(f.Value)
+		}
+		if alias := field.Tag.Get("alias"); alias != "" {
+			Alias(fs, alias, name)
+		}
+		if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+			RequireFlag(fs, name)
+		}
+	}
+}
+
+// Defines the flag name of fs backed by the field value fv.
+func bindField(fs *flag.FlagSet, fv reflect.Value, name, usage, sep string) error {
+	p := fv.Addr().Interface()
+	if v, ok := p.(flag.Value); ok {
+		fs.Var(v, name, usage)
+		return nil
+	}
+	switch p := p.(type) {
+	case *string:
+		fs.StringVar(p, name, *p, usage)
+	case *bool:
+		fs.BoolVar(p, name, *p, usage)
+	case *int:
+		fs.IntVar(p, name, *p, usage)
+	case *int64:
+		fs.Int64Var(p, name, *p, usage)
+	case *uint:
+		fs.UintVar(p, name, *p, usage)
+	case *uint64:
+		fs.Uint64Var(p, name, *p, usage)
+	case *float64:
+		fs.Float64Var(p, name, *p, usage)
+	case *time.Duration:
+		fs.DurationVar(p, name, *p, usage)
+	case *[]string:
+		fs.Var(&stringSlice{values: p, sep: sep, replace: true}, name, repeatableUsage(usage, sep))
+	case *[]int:
+		fs.Var(&intSlice{values: p, sep: sep, replace: true}, name, repeatableUsage(usage, sep))
+	default:
+		return fmt.Errorf("unsupported flag type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindOptions struct {
+	Port    int           `flag:"port" default:"8080" usage:"listen port"`
+	Host    string        `flag:"host" required:"true" usage:"listen host"`
+	Tags    []string      `flag:"tag" sep:","`
+	Wait    time.Duration `flag:"wait" alias:"w"`
+	Level   bindLevel     `flag:"level"`
+	Ignored string
+	commonOptions
+}
+
+type commonOptions struct {
+	Verbose bool `flag:"verbose"`
+}
+
+// bindLevel is a test flag.Value.
+type bindLevel int
+
+func (l *bindLevel) String() string { return strings.Repeat("v", int(*l)) }
+
+func (l *bindLevel) Set(s string) error {
+	*l = bindLevel(len(s))
+	return nil
+}
+
+// Tests if the tagged fields are bound to flags.
+func TestBindFlags(t *testing.T) {
+	var opts bindOptions
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	BindFlags(fs, &opts)
+	if opts.Port != 8080 || fs.Lookup("port").DefValue != "8080" {
+		t.Errorf("expected the default port, found %v", opts.Port)
+	}
+	err := fs.Parse([]string{"-host=localhost", "-tag=a,b", "-tag=c", "-w=2s", "-level=vvv", "-verbose"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bindOptions{Port: 8080, Host: "localhost", Tags: []string{"a", "b", "c"}, Wait: 2 * time.Second, Level: 3, commonOptions: commonOptions{Verbose: true}}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("expected %+v, found %+v", want, opts)
+	}
+	if fs.Lookup("Ignored") != nil {
+		t.Error("untagged fields were not expected to be bound")
+	}
+	if found := requiredFlags(&cmdCont{}, fs); !reflect.DeepEqual(found, []string{"host"}) {
+		t.Errorf("expected -host to be required, found %v", found)
+	}
+}
+
+// Tests if the values of a slice flag replace its default.
+func TestBindSliceDefault(t *testing.T) {
+	var opts struct {
+		Tags  []string `flag:"tag" default:"a"`
+		Ports []int    `flag:"port" sep:"," default:"80,443"`
+		Names []string `flag:"name"`
+	}
+	opts.Names = []string{"x"}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	BindFlags(fs, &opts)
+	if !reflect.DeepEqual(opts.Tags, []string{"a"}) || !reflect.DeepEqual(opts.Ports, []int{80, 443}) {
+		t.Errorf("expected the defaults, found %v, %v", opts.Tags, opts.Ports)
+	}
+	if err := fs.Parse([]string{"-tag=b", "-tag=c", "-port=8080", "-name=y"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opts.Tags, []string{"b", "c"}) || !reflect.DeepEqual(opts.Ports, []int{8080}) || !reflect.DeepEqual(opts.Names, []string{"y"}) {
+		t.Errorf("expected the defaults to be replaced, found %v, %v, %v", opts.Tags, opts.Ports, opts.Names)
+	}
+}
+
+// Tests if the flags marked by RequireFlag are checked.
+func TestRequireFlag(t *testing.T) {
+	resetForTesting("command1")
+
+	On("command1", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.String("host", "", "")
+		RequireFlag(fs, "host")
+		HideFlag(fs, "host")
+	}}, nil)
+	err := ParseErr()
+	if err == nil || !strings.Contains(err.Error(), "host") {
+		t.Errorf("expected -host to be missing, found %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clicmd adapts github.com/urfave/cli/v2 commands to
+// command.Cmd, so existing cli command implementations can be
+// dispatched by this package.
+package clicmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rakyll/command"
+	"github.com/urfave/cli/v2"
+)
+
+// On registers c under its name like command.On, with its usage as
+// the description. Its arguments are parsed by cli.
+func On(c *cli.Command, opts ...command.Option) {
+	command.On(c.Name, c.Usage, FromCLI(c), nil, append(opts, command.DisableFlagParsing())...)
+}
+
+// FromCLI returns a command.Cmd running c with the arguments.
+// Register it with command.DisableFlagParsing so the arguments are
+// passed to cli as they are. The errors of c are returned instead of
+// exiting, the flag errors as a *command.UsageError.
+func FromCLI(c *cli.Command) command.CmdE {
+	return &cmd{c: c, in: os.Stdin, out: os.Stdout, err: os.Stderr}
+}
+
+type cmd struct {
+	c *cli.Command
+	// the streams of the set the sub-command is registered in.
+	in       io.Reader
+	out, err io.Writer
+}
+
+// SetIO sets the streams the cli command reads and writes.
+func (w *cmd) SetIO(in io.Reader, out, err io.Writer) {
+	w.in, w.out, w.err = in, out, err
+}
+
+// Flags applies the cli flags to fs, so they are listed in the usage
+// of the sub-command.
+func (w *cmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	for _, f := range w.c.Flags {
+		f.Apply(fs)
+	}
+	return fs
+}
+
+func (w *cmd) Run(args []string) {
+	if err := w.RunE(args); err != nil {
+		fmt.Fprintln(w.err, err)
+	}
+}
+
+func (w *cmd) RunE(args []string) error {
+	app := &cli.App{
+		Name:            w.c.Name,
+		Usage:           w.c.Usage,
+		Description:     w.c.Description,
+		Flags:           w.c.Flags,
+		Commands:        w.c.Subcommands,
+		Before:          w.c.Before,
+		After:           w.c.After,
+		Action:          w.c.Action,
+		HideHelpCommand: true,
+		Reader:          w.in,
+		Writer:          w.out,
+		ErrWriter:       w.err,
+		OnUsageError: func(ctx *cli.Context, err error, isSubcommand bool) error {
+			if w.c.OnUsageError != nil {
+				err = w.c.OnUsageError(ctx, err, isSubcommand)
+			} else {
+				fmt.Fprintf(w.err, "Incorrect Usage: %v\n\n", err)
+				cli.ShowAppHelp(ctx)
+			}
+			if err == nil {
+				return nil
+			}
+			return &command.UsageError{Err: err}
+		},
+		// the errors are returned rather than exiting the process.
+		ExitErrHandler: func(*cli.Context, error) {},
+	}
+	return app.Run(append([]string{w.c.Name}, args...))
+}
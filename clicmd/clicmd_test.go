@@ -0,0 +1,79 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clicmd
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rakyll/command"
+	"github.com/urfave/cli/v2"
+)
+
+// Tests if the cli command runs with its flags and arguments.
+func TestFromCLI(t *testing.T) {
+	var name string
+	var args []string
+	c := &cli.Command{
+		Name:  "greet",
+		Usage: "greets",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name", Aliases: []string{"n"}, Destination: &name},
+		},
+		Action: func(ctx *cli.Context) error {
+			args = ctx.Args().Slice()
+			return nil
+		},
+	}
+	w := FromCLI(c)
+	if err := w.RunE([]string{"-n", "x", "arg"}); err != nil {
+		t.Fatal(err)
+	}
+	if name != "x" || !reflect.DeepEqual(args, []string{"arg"}) {
+		t.Errorf("unexpected flags or args: %q %v", name, args)
+	}
+	fs := w.Flags(flag.NewFlagSet("greet", flag.ContinueOnError))
+	if fs.Lookup("name") == nil || fs.Lookup("n") == nil {
+		t.Error("the cli flags were expected to be translated")
+	}
+}
+
+// Tests if the errors of the cli command are returned and its output
+// is written to the streams of the set.
+func TestFromCLIError(t *testing.T) {
+	c := &cli.Command{
+		Name:   "greet",
+		Flags:  []cli.Flag{&cli.StringFlag{Name: "name", Usage: "name to greet"}},
+		Action: func(*cli.Context) error { return nil },
+	}
+	var out bytes.Buffer
+	set := command.New("tool")
+	set.SetIO(nil, &out, &out)
+	set.On("greet", "", FromCLI(c), nil, command.DisableFlagParsing())
+	err := set.ParseAndRunE([]string{"greet", "--unknown"})
+	if e, ok := err.(*command.UsageError); !ok || e.ExitCode() != 2 {
+		t.Errorf("expected a usage error, found %v", err)
+	}
+	if !strings.Contains(out.String(), "--name") {
+		t.Errorf("expected the usage on the streams of the set, found %q", out.String())
+	}
+	c.Action = func(*cli.Context) error { return cli.Exit("failed", 3) }
+	if err := set.ParseAndRunE([]string{"greet"}); err == nil || err.Error() != "failed" {
+		t.Errorf("expected the error of the action, found %v", err)
+	}
+}
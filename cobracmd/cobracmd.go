@@ -0,0 +1,85 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cobracmd adapts github.com/spf13/cobra commands to
+// command.Cmd, so cobra command trees can be mixed with sub-commands
+// of this package.
+package cobracmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/rakyll/command"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// On registers c for the provided sub-command name like command.On,
+// with the short description of c. Its arguments, including its own
+// sub-commands, flags and help, are handled by cobra.
+func On(name string, c *cobra.Command, opts ...command.Option) {
+	command.On(name, c.Short, FromCobra(c), nil, append(opts, command.DisableFlagParsing())...)
+}
+
+// FromCobra returns a command.Cmd executing c with the arguments.
+// Register it with command.DisableFlagParsing so the arguments are
+// passed to cobra as they are. The errors of c are returned instead of
+// printed by cobra, the flag errors as a *command.UsageError.
+func FromCobra(c *cobra.Command) command.CmdE {
+	c.SilenceErrors = true
+	flagError := c.FlagErrorFunc()
+	c.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		if err := flagError(c, err); err != nil {
+			return &command.UsageError{Err: err}
+		}
+		return nil
+	})
+	return &cmd{c: c}
+}
+
+type cmd struct {
+	c *cobra.Command
+}
+
+// Flags defines the flags of the cobra command on fs, so they are
+// listed in the usage of the sub-command.
+func (w *cmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	w.c.Flags().VisitAll(func(f *pflag.Flag) {
+		if fs.Lookup(f.Name) == nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	return fs
+}
+
+// SetIO sets the streams of the cobra command to the ones of the set
+// the sub-command is registered in.
+func (w *cmd) SetIO(in io.Reader, out, err io.Writer) {
+	w.c.SetIn(in)
+	w.c.SetOut(out)
+	w.c.SetErr(err)
+}
+
+func (w *cmd) Run(args []string) {
+	if err := w.RunE(args); err != nil {
+		fmt.Fprintln(w.c.ErrOrStderr(), err)
+	}
+}
+
+func (w *cmd) RunE(args []string) error {
+	w.c.SetArgs(args)
+	return w.c.Execute()
+}
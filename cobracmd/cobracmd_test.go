@@ -0,0 +1,71 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobracmd
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rakyll/command"
+	"github.com/spf13/cobra"
+)
+
+// Tests if the cobra command runs with its flags and arguments.
+func TestFromCobra(t *testing.T) {
+	var name string
+	var args []string
+	c := &cobra.Command{
+		Use:   "greet",
+		Short: "greets",
+		Run: func(cmd *cobra.Command, a []string) {
+			args = a
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "name to greet")
+	w := FromCobra(c)
+	if err := w.RunE([]string{"--name", "x", "arg"}); err != nil {
+		t.Fatal(err)
+	}
+	if name != "x" || !reflect.DeepEqual(args, []string{"arg"}) {
+		t.Errorf("unexpected flags or args: %q %v", name, args)
+	}
+	if fs := w.Flags(flag.NewFlagSet("greet", flag.ContinueOnError)); fs.Lookup("name") == nil {
+		t.Error("the cobra flags were expected to be listed")
+	}
+}
+
+// Tests if the errors of the cobra command are returned and its
+// output is written to the streams of the set.
+func TestFromCobraError(t *testing.T) {
+	c := &cobra.Command{
+		Use: "greet",
+		Run: func(cmd *cobra.Command, a []string) {},
+	}
+	c.Flags().String("name", "", "name to greet")
+	var out bytes.Buffer
+	set := command.New("tool")
+	set.SetIO(nil, &out, &out)
+	set.On("greet", "", FromCobra(c), nil, command.DisableFlagParsing())
+	err := set.ParseAndRunE([]string{"greet", "--unknown"})
+	if e, ok := err.(*command.UsageError); !ok || e.ExitCode() != 2 {
+		t.Errorf("expected a usage error, found %v", err)
+	}
+	if !strings.Contains(out.String(), "--name") {
+		t.Errorf("expected the usage on the streams of the set, found %q", out.String())
+	}
+}
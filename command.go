@@ -18,24 +18,177 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 )
 
-// A map of all of the registered sub-commands.
-var cmds map[string]*cmdCont = make(map[string]*cmdCont)
+// CommandSet is a set of sub-commands with their own global flags.
+// The package-level functions use a default set whose global flags
+// are the flag package's command line flags.
+//
+// Registering, deregistering and looking up sub-commands, parsing and
+// running are safe for concurrent use, e.g. registering from init
+// functions of several packages or from parallel tests. The other
+// configuration methods should be called before parsing.
+type CommandSet struct {
+	// name of the program, os.Args[0] if empty.
+	name string
+	// global flags, flag.CommandLine if nil.
+	flags *flag.FlagSet
+	// flags inherited by the sub-commands, nil if there are none.
+	persistent *flag.FlagSet
+	// persistent flags provided before the sub-command name by the
+	// last parse.
+	persistentProvided map[string]bool
+	// set the set is nested in and its sub-command name there.
+	parent  *CommandSet
+	cmdName string
+
+	// guards cmds, registered and matching.
+	mu sync.Mutex
+	// A map of all of the registered sub-commands.
+	cmds map[string]*cmdCont
+	// Matching subcommand, set by Parse.
+	matching *parseResult
+	// Number of sub-commands registered so far.
+	registered int
+	// runs if no sub-command name is given, nil if unset.
+	root *cmdCont
+	// name of the sub-command the arguments are parsed by if they
+	// don't start with a sub-command name.
+	defaultCmd string
+	resolver   Resolver
+	// exits the process, the parent's or os.Exit if nil.
+	exitFunc func(code int)
+	// whether sub-command flags can follow positionals.
+	interspersed      bool
+	groupedShortFlags bool
+	// normalizes flag names before they are looked up, nil if unset.
+	normalize func(name string) string
+	// streams, the standard ones if nil.
+	in  io.Reader
+	out io.Writer
+	err io.Writer
+	// How Parse and ParseAndRun behave if parsing fails.
+	errorHandling flag.ErrorHandling
+
+	topics             map[string]*helpTopic
+	deprecatedFlags    map[string]map[string]string
+	warnedFlags        map[string]bool
+	sortMode           SortMode
+	sortLess           func(a, b string) bool
+	traceEnabled       bool
+	multiCallEnabled   bool
+	multiCallPrefix    string
+	pipelineSeparator  string
+	configFlagEnabled  bool
+	configFile         string
+	envPrefix          string
+	dotEnv             string
+	responseFiles      bool
+	interactive        bool
+	profilesEnabled    bool
+	profiles           map[string]map[string]string
+	profile            string
+	providers          []namedProvider
+	requiredFlagErrorf func(cmd string, missing []string) string
+}
+
+// New returns a new, empty command set for the named program with its
+// own global flags.
+func New(name string) *CommandSet {
+	return NewWithErrorHandling(name, flag.ExitOnError)
+}
+
+// NewWithErrorHandling returns a new, empty command set like New,
+// whose Parse and ParseAndRun behave as the provided error handling
+// property if the arguments don't match the configuration. With
+// flag.ContinueOnError they print the problem and return without
+// running a sub-command, with flag.PanicOnError they panic with the
+// error. ParseErr and ParseAndRunE return the error in every mode.
+func NewWithErrorHandling(name string, errorHandling flag.ErrorHandling) *CommandSet {
+	s := newCommandSet()
+	s.name = name
+	// parse errors are handled by the set.
+	s.flags = flag.NewFlagSet(name, flag.ContinueOnError)
+	s.errorHandling = errorHandling
+	return s
+}
 
-// Matching subcommand.
-var matchingCmd *cmdCont
+// ErrorHandling returns the error handling behavior of the set.
+func (s *CommandSet) ErrorHandling() flag.ErrorHandling {
+	return s.errorHandling
+}
 
-// Arguments to call subcommand's runnable.
-var args []string
+func newCommandSet() *CommandSet {
+	return &CommandSet{
+		cmds:               make(map[string]*cmdCont),
+		topics:             make(map[string]*helpTopic),
+		deprecatedFlags:    make(map[string]map[string]string),
+		warnedFlags:        make(map[string]bool),
+		errorHandling:      flag.ExitOnError,
+		requiredFlagErrorf: defaultRequiredFlagError,
+	}
+}
 
-// Flag to determine whether help is
-// asked for subcommand or not
-var flagHelp *bool
+// The command set used by the package-level functions.
+var defaultSet = newCommandSet()
+
+// Terminates the process with the given status code.
+var exit = os.Exit
+
+// SetExit sets the function called to exit the process with a status
+// code, e.g. to intercept exits in tests or long-running hosts. Errors
+// of the global flags of the package-level set are still handled by
+// the flag package.
+func SetExit(f func(code int)) {
+	defaultSet.SetExit(f)
+}
+
+// SetExit sets the exit function of the set.
+func (s *CommandSet) SetExit(f func(code int)) {
+	s.exitFunc = f
+}
+
+// Exits with the exit function of the set.
+func (s *CommandSet) terminate(code int) {
+	switch {
+	case s.exitFunc != nil:
+		s.exitFunc(code)
+	case s.parent != nil:
+		s.parent.terminate(code)
+	default:
+		exit(code)
+	}
+}
+
+// Flags returns the global flag set. Define the global flags on it
+// before calling Parse.
+func (s *CommandSet) Flags() *flag.FlagSet {
+	if s.flags == nil {
+		return flag.CommandLine
+	}
+	return s.flags
+}
+
+// Returns the name of the program, followed by the sub-command
+// names leading to the set if it is nested.
+func (s *CommandSet) program() string {
+	if s.parent != nil {
+		return s.parent.program() + " " + s.cmdName
+	}
+	if s.name == "" {
+		return os.Args[0]
+	}
+	return s.name
+}
 
 // Cmd represents a sub command, allowing to define subcommand
 // flags and runnable to run once arguments match the subcommand
@@ -45,56 +198,347 @@ type Cmd interface {
 	Run(args []string)
 }
 
+// CmdContext is implemented by sub-commands that receive a context
+// for cancellation and deadlines. RunContext is called instead of Run,
+// its error is returned by the error-returning entrypoints or printed
+// before exiting with 1 by the others.
+type CmdContext interface {
+	Cmd
+	RunContext(ctx context.Context, args []string) error
+}
+
+// CmdE is implemented by sub-commands whose runnable fails with an
+// error. RunE is called instead of Run, its error is handled like
+// the error of RunContext.
+type CmdE interface {
+	Cmd
+	RunE(args []string) error
+}
+
+// ExitCoder is implemented by errors that choose the exit code of
+// the process if a sub-command fails with them. Errors not
+// implementing it exit with 1.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// GlobalFlagsCmd is implemented by sub-commands whose flags depend
+// on the global flags. FlagsWithGlobals is called instead of Flags
+// with the global flag set, which is parsed when the sub-command
+// arguments are parsed. Flags of the sub-command may be defined
+// conditionally on the global flag values.
+type GlobalFlagsCmd interface {
+	Cmd
+	FlagsWithGlobals(fs *flag.FlagSet, globals *flag.FlagSet) *flag.FlagSet
+}
+
+// ExitCmd is implemented by sub-commands that choose the exit code
+// of the process once their runnable returns.
+type ExitCmd interface {
+	Cmd
+	ExitCode() int
+}
+
 type cmdCont struct {
 	name          string
 	desc          string
 	command       Cmd
 	requiredFlags []string
+	// builds command on first use if it's registered lazily.
+	factory func() Cmd
+
+	disableFlagParsing bool
+	hidden             bool
+	args               []string
+	arity              *arity
+	argsHelp           map[string]string
+	argFlags           map[string]string
+	argVars            map[string]flag.Value
+	argValidators      map[string][]ArgValidator
+	annotations        map[string]string
+	in                 io.Reader
+	out                io.Writer
+	err                io.Writer
+	aliases            []string
+	long               string
+	examples           []string
+	seeAlso            []string
+	owner              string
+	confirm            string
+	// deprecation message and replacement, deprecated if the
+	// message isn't empty.
+	deprecated       string
+	replacement      string
+	warned           bool
+	flagGroups       []flagGroup
+	passUnknownFlags bool
+	flagRelations    []flagRelation
+
+	// registration order of the sub-command.
+	order int
+}
+
+// Option configures a sub-command at registration time.
+type Option func(*cmdCont)
+
+// DisableFlagParsing makes Parse skip flag parsing for the sub-command,
+// all of the arguments following the sub-command name are passed
+// to its runnable verbatim. A leading "--" terminator is dropped.
+// Useful for commands that forward their arguments to another program.
+func DisableFlagParsing() Option {
+	return func(cont *cmdCont) {
+		cont.disableFlagParsing = true
+	}
+}
+
+// Hidden omits the sub-command from the usage, completion and
+// suggestions. It still runs if its name is provided.
+func Hidden() Option {
+	return func(cont *cmdCont) {
+		cont.hidden = true
+	}
 }
 
 // Registers a Cmd for the provided sub-command name. E.g. name is the
 // `status` in `git status`.
-func On(name, description string, command Cmd, requiredFlags []string) {
-	cmds[name] = &cmdCont{
+func On(name, description string, command Cmd, requiredFlags []string, opts ...Option) {
+	defaultSet.On(name, description, command, requiredFlags, opts...)
+}
+
+// On registers a Cmd for the provided sub-command name in the set.
+func (s *CommandSet) On(name, description string, command Cmd, requiredFlags []string, opts ...Option) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.on(name, description, command, requiredFlags, opts...)
+}
+
+// Registers the sub-command, s.mu must be held.
+func (s *CommandSet) on(name, description string, command Cmd, requiredFlags []string, opts ...Option) *cmdCont {
+	s.registered++
+	cont := &cmdCont{
 		name:          name,
 		desc:          description,
 		command:       command,
 		requiredFlags: requiredFlags,
+		order:         s.registered,
+	}
+	for _, opt := range opts {
+		opt(cont)
+	}
+	s.cmds[name] = cont
+	for _, alias := range cont.aliases {
+		s.cmds[alias] = cont
 	}
+	return cont
+}
+
+// Off deregisters the named sub-command. It reports whether the
+// sub-command was registered.
+func Off(name string) bool {
+	return defaultSet.Off(name)
+}
+
+// Off deregisters the named sub-command of the set.
+func (s *CommandSet) Off(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cont, ok := s.cmds[name]
+	if !ok {
+		return false
+	}
+	delete(s.cmds, cont.name)
+	for _, alias := range cont.aliases {
+		delete(s.cmds, alias)
+	}
+	return true
+}
+
+// Replace swaps the Cmd of the named sub-command, keeping its
+// description, required flags and options, e.g. to stub out a
+// command in tests. It reports whether the sub-command was
+// registered.
+func Replace(name string, command Cmd) bool {
+	return defaultSet.Replace(name, command)
+}
+
+// Replace swaps the Cmd of the named sub-command of the set.
+func (s *CommandSet) Replace(name string, command Cmd) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cont, ok := s.cmds[name]
+	if !ok {
+		return false
+	}
+	cont.command, cont.factory = command, nil
+	return true
+}
+
+// UsageText holds the text printed by the usage guides. Texts
+// containing a %s verb are formatted with the program name.
+type UsageText struct {
+	Usage         string // e.g. "Usage: %s <command>"
+	UsageOf       string // e.g. "Usage of %s:"
+	Commands      string // e.g. "where <command> is one of:"
+	Flags         string // e.g. "available flags:"
+	RequiredFlags string // e.g. "required flags:"
+	Topics        string // e.g. "additional help topics:"
+	Help          string // e.g. "%s <command> -h for subcommand help"
+	Default       string // e.g. "(default)"
+	Examples      string // e.g. "examples:"
+	SeeAlso       string // e.g. "see also:"
+	Owner         string // e.g. "owner: %s"
+	Aliases       string // e.g. "aliases: %s"
+	Deprecated    string // e.g. "deprecated: %s"
+	Constraints   string // e.g. "flag constraints:"
+	Arguments     string // e.g. "arguments:"
+}
+
+// UsageStrings is the text used by Usage and the subcommand usage,
+// override it to localize or rebrand the usage guides.
+var UsageStrings = UsageText{
+	Usage:         "Usage: %s <command>",
+	UsageOf:       "Usage of %s:",
+	Commands:      "where <command> is one of:",
+	Flags:         "available flags:",
+	RequiredFlags: "required flags:",
+	Topics:        "additional help topics:",
+	Help:          "%s <command> -h for subcommand help",
+	Default:       "(default)",
+	Examples:      "examples:",
+	SeeAlso:       "see also:",
+	Owner:         "owner: %s",
+	Aliases:       "aliases: %s",
+	Deprecated:    "deprecated: %s",
+	Constraints:   "flag constraints:",
+	Arguments:     "arguments:",
 }
 
 // Prints the usage.
 func Usage() {
-	program := os.Args[0]
-	if len(cmds) == 0 {
+	defaultSet.Usage()
+}
+
+// Usage prints the usage of the set.
+func (s *CommandSet) Usage() {
+	s.printUsage(s.stderr())
+}
+
+// UsageString returns the usage as printed by Usage.
+func UsageString() string {
+	return defaultSet.UsageString()
+}
+
+// UsageString returns the usage of the set as printed by Usage.
+func (s *CommandSet) UsageString() string {
+	var buf bytes.Buffer
+	s.printUsage(&buf)
+	return buf.String()
+}
+
+// SubcommandUsageString returns the usage of the named sub-command
+// as printed if -h is provided to it.
+func SubcommandUsageString(name string) (string, error) {
+	return defaultSet.SubcommandUsageString(name)
+}
+
+// SubcommandUsageString returns the usage of the named sub-command
+// of the set as printed if -h is provided to it.
+func (s *CommandSet) SubcommandUsageString(name string) (string, error) {
+	var buf bytes.Buffer
+	if err := s.PrintCommandHelp(name, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// PrintCommandHelp writes the usage of the named sub-command to w,
+// as printed if -h is provided to it. It doesn't depend on Parse.
+func PrintCommandHelp(name string, w io.Writer) error {
+	return defaultSet.PrintCommandHelp(name, w)
+}
+
+// PrintCommandHelp writes the usage of the named sub-command
+// of the set to w.
+func (s *CommandSet) PrintCommandHelp(name string, w io.Writer) error {
+	cont, ok := s.cmds[name]
+	if !ok {
+		return &UnknownCommandError{Name: name}
+	}
+	s.printSubcommandUsage(w, cont)
+	return nil
+}
+
+func (s *CommandSet) printUsage(w io.Writer) {
+	program := s.program()
+	if len(s.cmds) == 0 {
 		// no subcommands
-		fmt.Fprintf(os.Stderr, "Usage of %s:\n", program)
-		flag.PrintDefaults()
+		fmt.Fprintf(w, UsageStrings.UsageOf+"\n", program)
+		s.printGlobalDefaults(w)
 		return
 	}
 
-	fmt.Fprintf(os.Stderr, "Usage: %s <command>\n\n", program)
-	fmt.Fprintf(os.Stderr, "where <command> is one of:\n")
-	for name, cont := range cmds {
-		fmt.Fprintf(os.Stderr, "  %-15s %s\n", name, cont.desc)
+	fmt.Fprintf(w, UsageStrings.Usage+"\n\n", program)
+	fmt.Fprintf(w, "%s\n", UsageStrings.Commands)
+	for _, name := range s.sortedCmdNames() {
+		desc := s.cmds[name].desc
+		if name == s.defaultCmd {
+			desc = strings.TrimSpace(desc + " " + UsageStrings.Default)
+		}
+		if s.cmds[name].deprecated != "" {
+			desc = strings.TrimSpace(desc + " (" + fmt.Sprintf(UsageStrings.Deprecated, s.cmds[name].deprecated) + ")")
+		}
+		fmt.Fprintf(w, "  %-15s %s\n", name, desc)
 	}
+	s.printTopics(w)
 
-	if numOfGlobalFlags() > 0 {
-		fmt.Fprintf(os.Stderr, "\navailable flags:\n")
-		flag.PrintDefaults()
+	if s.numOfGlobalFlags() > 0 {
+		fmt.Fprintf(w, "\n%s\n", UsageStrings.Flags)
+		s.printGlobalDefaults(w)
 	}
-	fmt.Fprintf(os.Stderr, "\n%s <command> -h for subcommand help\n", program)
+	fmt.Fprintf(w, "\n"+UsageStrings.Help+"\n", program)
+}
+
+// Prints the defaults of the global flags to w.
+func (s *CommandSet) printGlobalDefaults(w io.Writer) {
+	fs := s.Flags()
+	defer fs.SetOutput(fs.Output())
+	defer s.annotateEnv(fs, "")()
+	fs.SetOutput(w)
+	printDefaults(fs)
 }
 
-func subcommandUsage(cont *cmdCont) {
-	fmt.Fprintf(os.Stderr, "Usage of %s %s:\n", os.Args[0], cont.name)
+func (s *CommandSet) subcommandUsage(cont *cmdCont) {
+	s.printSubcommandUsage(s.stderr(), cont)
+}
+
+func (s *CommandSet) printSubcommandUsage(w io.Writer, cont *cmdCont) {
+	if n, ok := cont.cmd().(*nestedCmd); ok {
+		n.set.printUsage(w)
+		return
+	}
+	fmt.Fprintf(w, UsageStrings.UsageOf+"\n", s.program()+" "+cont.name+cont.argsUsage())
+	if len(cont.aliases) > 0 {
+		fmt.Fprintf(w, UsageStrings.Aliases+"\n", strings.Join(cont.aliases, ", "))
+	}
+	if cont.deprecated != "" {
+		fmt.Fprintf(w, UsageStrings.Deprecated+"\n", cont.deprecated)
+	}
+	if cont.long != "" {
+		fmt.Fprintf(w, "\n%s\n\n", cont.long)
+	}
 	// should only output sub command flags, ignore h flag.
-	fs := matchingCmd.command.Flags(flag.NewFlagSet(cont.name, flag.ContinueOnError))
-	fs.PrintDefaults()
-	if len(cont.requiredFlags) > 0 {
-		fmt.Fprintf(os.Stderr, "\nrequired flags:\n")
-		fmt.Fprintf(os.Stderr, "  %s\n\n", strings.Join(cont.requiredFlags, ", "))
+	fs := s.flagSet(cont, flag.ContinueOnError)
+	fs.SetOutput(w)
+	s.annotateEnv(fs, cont.name)
+	printDefaults(fs)
+	cont.printArgsHelp(w)
+	if required := requiredFlags(cont, fs); len(required) > 0 {
+		fmt.Fprintf(w, "\n%s\n", UsageStrings.RequiredFlags)
+		fmt.Fprintf(w, "  %s\n\n", strings.Join(required, ", "))
 	}
+	cont.printConstraints(w)
+	cont.printMetadata(w)
 }
 
 // Parses the flags and leftover arguments to match them with a
@@ -104,68 +548,489 @@ func subcommandUsage(cont *cmdCont) {
 // A usage with flag defaults will be printed if provided arguments
 // don't match the configuration.
 // Global flags are accessible once Parse executes.
+// If -h is provided before the sub-command name, the usage is printed
+// and the program exits with 0. If it's provided after, Run prints
+// the sub-command usage instead of running it.
+// A "--" preceding the sub-command name terminates the global flags,
+// a "--" following it terminates the sub-command flags and the
+// rest of the arguments are passed to the runnable as they are.
 func Parse() {
-	flag.Parse()
+	defaultSet.Parse(os.Args[1:])
+}
+
+// Parse parses the arguments, which should not include the program
+// name, like the package-level Parse.
+func (s *CommandSet) Parse(arguments []string) {
+	s.parseOrExit(arguments)
+}
+
+// parseResult is the sub-command matched by parsing the arguments.
+type parseResult struct {
+	// set is the deepest command set the arguments are parsed by,
+	// cont is nil if they don't match one of its sub-commands.
+	set  *CommandSet
+	cont *cmdCont
+	// flags is nil if the sub-command disables flag parsing.
+	flags *flag.FlagSet
+	help  bool
+	args  []string
+	// passthrough are the arguments following a "--" terminating the
+	// flags of the sub-command, nil if there is none.
+	passthrough []string
+	// sources of the flags which are set, by flag name.
+	sources map[string]string
+}
+
+// ErrNoCommand is returned if no sub-command name is provided.
+var ErrNoCommand = errors.New("command: no command provided")
+
+// UnknownCommandError is returned if the provided sub-command name
+// doesn't match a registered sub-command.
+type UnknownCommandError struct {
+	Name string
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("command: unknown command %q", e.Name)
+}
+
+// ExitError is returned if a sub-command implementing ExitCmd
+// exits with a non-zero code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command: exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code chosen by the sub-command.
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// UsageError is returned by sub-commands parsing their own arguments,
+// e.g. with another flag package, if they can't be parsed. The program
+// exits with 2 like for the parse errors of the set.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the parse error.
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns 2.
+func (e *UsageError) ExitCode() int {
+	return 2
+}
+
+// Parses the arguments, sets the matching sub-command and its
+// arguments. Prints the usage and exits, or behaves as the error
+// handling property of the set, if they don't match the
+// configuration.
+func (s *CommandSet) parseOrExit(arguments []string) *parseResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// exits and panics are handled below.
+	res, err := s.parse(arguments, flag.ContinueOnError)
+	s.matching = res
+	if err == flag.ErrHelp {
+		// the usage is printed by the flag package.
+		if s.errorHandling == flag.ExitOnError {
+			s.terminate(0)
+		}
+		s.matching = nil
+		return nil
+	}
+	if err != nil {
+		code := 1
+		switch e := err.(type) {
+		case Errors:
+			fmt.Fprintf(res.set.stderr(), "%v\n\n", e)
+			res.set.subcommandUsage(res.cont)
+		case *UnknownCommandError:
+			res.set.printUnknown(res.set.stderr(), res.set.program(), e.Name)
+			res.set.Usage()
+		default:
+			if err == ErrNoCommand {
+				res.set.Usage()
+			} else if res != nil && res.cont == nil {
+				// a resolver error.
+				fmt.Fprintf(res.set.stderr(), "%v\n\n", err)
+				res.set.Usage()
+			} else {
+				// printed by the flag package, which exits with 2.
+				code = 2
+			}
+		}
+		switch s.errorHandling {
+		case flag.ExitOnError:
+			s.terminate(code)
+		case flag.PanicOnError:
+			panic(err)
+		}
+		s.matching = nil
+		return nil
+	}
+	return res
+}
+
+// Parses the global flags and the arguments of the matching
+// sub-command, recursively for nested sets. The result is nil if no
+// sub-commands are registered.
+func (s *CommandSet) parse(arguments []string, handling flag.ErrorHandling) (*parseResult, error) {
+	globals := s.Flags()
+	s.defineTraceFlag(globals)
+	if s.flags == nil {
+		flag.Usage = s.Usage
+	} else {
+		globals.Usage = s.Usage
+	}
+	if s.err != nil || s.parent != nil {
+		globals.SetOutput(s.stderr())
+	}
+	s.definePersistentFlags(globals)
+	s.defineConfigFlag(globals)
+	s.defineProfileFlag(globals)
+	setValueIO(globals, s.stdin(), s.stdout(), s.stderr())
+	if cont, words, ok := s.compLineCmd(); ok {
+		return &parseResult{set: s, cont: cont, args: words}, nil
+	}
+	arguments, err := s.expandResponseFiles(arguments)
+	if err != nil {
+		return &parseResult{set: s}, err
+	}
+	// the arguments of a multi-call binary are the sub-command's.
+	multiCallCont, multiCall := s.multiCallCmd()
+	if multiCall {
+		globals.Parse(nil)
+	} else {
+		arguments = s.normalizeFlags(globals, arguments, false)
+		if err := globals.Parse(s.withDefaultCmd(arguments)); err != nil {
+			return nil, err
+		}
+	}
+	s.persistentProvided = s.providedPersistentFlags(globals)
+	if err := s.loadDotEnv(); err != nil {
+		return &parseResult{set: s}, err
+	}
+	if _, err := s.applyProfile(globals, ""); err != nil {
+		return &parseResult{set: s}, err
+	}
+	if err := s.applyEnv(globals, ""); err != nil {
+		return &parseResult{set: s}, err
+	}
+	if err := s.applyConfigFile(globals, ""); err != nil {
+		return &parseResult{set: s}, err
+	}
+	if err := s.applyProviders(globals, "", nil); err != nil {
+		return &parseResult{set: s}, err
+	}
+	if multiCall {
+		return s.parseCmd(multiCallCont, arguments, handling)
+	}
 	// if there are no subcommands registered,
 	// return immediately
-	if len(cmds) < 1 {
-		return
+	if len(s.cmds) < 1 {
+		return s.rootResult(globals.Args()), nil
 	}
 
-	flag.Usage = Usage
-	if flag.NArg() < 1 {
-		flag.Usage()
-		os.Exit(1)
+	if globals.NArg() < 1 {
+		if s.root != nil {
+			return s.rootResult(nil), nil
+		}
+		return &parseResult{set: s}, ErrNoCommand
 	}
 
-	name := flag.Arg(0)
-	if cont, ok := cmds[name]; ok {
-		fs := cont.command.Flags(flag.NewFlagSet(name, flag.ExitOnError))
-		flagHelp = fs.Bool("h", false, "")
-		fs.Parse(flag.Args()[1:])
-		args = fs.Args()
-		matchingCmd = cont
+	cont, rest, err := s.resolve(globals.Args())
+	if err != nil {
+		return &parseResult{set: s}, err
+	}
+	cont = s.warnDeprecated(cont)
+	res, err := s.parseCmd(cont, rest, handling)
+	if n, ok := cont.cmd().(*nestedCmd); ok && err == nil {
+		return n.set.parse(res.args, handling)
+	}
+	return res, err
+}
 
-		// Check for required flags.
-		flagMap := make(map[string]bool)
-		for _, flagName := range cont.requiredFlags {
-			flagMap[flagName] = true
-		}
-		fs.Visit(func(f *flag.Flag) {
-			delete(flagMap, f.Name)
-		})
-		if len(flagMap) > 0 {
-			subcommandUsage(matchingCmd)
-			os.Exit(1)
+// Parses the arguments following the sub-command name.
+// An error is returned if the arguments can't be parsed, or Errors
+// if they fail the validation. Validation is skipped if help is asked.
+func (s *CommandSet) parseCmd(cont *cmdCont, arguments []string, handling flag.ErrorHandling) (*parseResult, error) {
+	if cont.disableFlagParsing {
+		res := &parseResult{set: s, cont: cont, args: arguments}
+		if len(arguments) > 0 && arguments[0] == "--" {
+			res.args = arguments[1:]
+			res.passthrough = res.args
 		}
+		return res, nil
+	}
+	fs := s.flagSet(cont, handling)
+	fs.SetOutput(s.stderr())
+	in, out, errOut := s.streams(cont)
+	setValueIO(fs, in, out, errOut)
+	fs.Usage = func() {
+		s.subcommandUsage(cont)
+	}
+	s.defineDeprecatedFlags(fs, cont)
+	help := fs.Bool("h", false, "")
+	var args, passthrough, unknown []string
+	var err error
+	arguments = s.normalizeFlags(fs, arguments, s.interspersed)
+	if s.groupedShortFlags {
+		arguments = expandShortFlags(fs, arguments, s.interspersed)
+	}
+	if cont.passUnknownFlags {
+		arguments, unknown = splitUnknownFlags(fs, arguments)
+	}
+	if s.interspersed {
+		args, passthrough, err = parseInterspersed(fs, arguments)
 	} else {
-		flag.Usage()
-		os.Exit(1)
+		err = fs.Parse(arguments)
+		args = fs.Args()
+		passthrough = terminated(arguments, args)
+	}
+	if len(unknown) > 0 {
+		args = append(unknown, args...)
+	}
+	res := &parseResult{set: s, cont: cont, flags: fs, help: *help, args: args, passthrough: passthrough}
+	if err != nil {
+		return res, err
+	}
+	if res.help {
+		return res, nil
+	}
+	s.markPersistentFlags(fs)
+	res.sources = markSources(fs, nil, sourceFlag)
+	source, err := s.applyProfile(fs, cont.name)
+	if err != nil {
+		return res, err
+	}
+	markSources(fs, res.sources, source)
+	if err := s.applyEnv(fs, cont.name); err != nil {
+		return res, err
+	}
+	markSources(fs, res.sources, sourceEnv)
+	if err := s.applyConfigFile(fs, cont.name); err != nil {
+		return res, err
+	}
+	markSources(fs, res.sources, sourceConfig)
+	if err := s.applyProviders(fs, cont.name, res.sources); err != nil {
+		return res, err
+	}
+	if err := s.promptRequiredFlags(cont, fs); err != nil {
+		return res, err
 	}
+	markSources(fs, res.sources, sourcePrompt)
+	if errs := s.validate(cont, fs, res.args); len(errs) > 0 {
+		return res, errs
+	}
+	return res, nil
+}
+
+// Returns a new flag set with the flags of the sub-command.
+func (s *CommandSet) flagSet(cont *cmdCont, handling flag.ErrorHandling) *flag.FlagSet {
+	fs := flag.NewFlagSet(cont.name, handling)
+	if c, ok := cont.cmd().(GlobalFlagsCmd); ok {
+		fs = c.FlagsWithGlobals(fs, s.Flags())
+	} else {
+		fs = cont.cmd().Flags(fs)
+	}
+	s.definePersistentFlags(fs)
+	s.defineConfigFlag(fs)
+	s.defineProfileFlag(fs)
+	defineConfirmFlags(fs, cont)
+	return fs
+}
+
+// ParseErr parses the arguments like Parse, but returns an error
+// instead of printing the usage and exiting if they don't match the
+// configuration. No sub-command is matched if an error is returned,
+// Run and RunErr don't run one. It returns flag.ErrHelp if -h is
+// provided before the sub-command name.
+func ParseErr() error {
+	return defaultSet.ParseErr(os.Args[1:])
+}
+
+// ParseErr parses the arguments like the package-level ParseErr.
+func (s *CommandSet) ParseErr(arguments []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, err := s.parse(arguments, flag.ContinueOnError)
+	if err != nil {
+		res = nil
+	}
+	s.matching = res
+	return err
 }
 
 // Runs the subcommand's runnable. If there is no subcommand
-// registered, it silently returns.
+// registered, it silently returns. If the subcommand implements
+// ExitCmd, the process exits with its exit code after it runs.
 func Run() {
-	if matchingCmd != nil {
-		if *flagHelp {
-			subcommandUsage(matchingCmd)
-			return
+	defaultSet.Run()
+}
+
+// Run runs the runnable of the subcommand matched by Parse.
+func (s *CommandSet) Run() {
+	s.mu.Lock()
+	res := s.matching
+	s.mu.Unlock()
+	if res != nil && res.cont != nil {
+		res.run(context.Background())
+	}
+}
+
+// RunErr runs the subcommand's runnable like Run, but returns an
+// ExitError instead of exiting if the subcommand implements ExitCmd
+// and chooses a non-zero exit code.
+func RunErr() error {
+	return defaultSet.RunErr()
+}
+
+// RunErr runs the subcommand's runnable like the package-level RunErr.
+func (s *CommandSet) RunErr() error {
+	return s.RunContext(context.Background())
+}
+
+// RunContext runs the subcommand's runnable like RunErr, passing
+// ctx to it if the subcommand implements CmdContext.
+func RunContext(ctx context.Context) error {
+	return defaultSet.RunContext(ctx)
+}
+
+// RunContext runs the subcommand's runnable like the package-level
+// RunContext.
+func (s *CommandSet) RunContext(ctx context.Context) error {
+	s.mu.Lock()
+	res := s.matching
+	s.mu.Unlock()
+	if res == nil || res.cont == nil {
+		return nil
+	}
+	return res.runErr(ctx)
+}
+
+// Runs the runnable of the matched sub-command and exits with its
+// exit code if it implements ExitCmd. If it fails, the error is
+// printed and the program exits with its ExitCoder code, or 1.
+func (res *parseResult) run(ctx context.Context) {
+	if err := res.runErr(ctx); err != nil {
+		code := 1
+		if e, ok := err.(ExitCoder); ok {
+			code = e.ExitCode()
 		}
-		matchingCmd.command.Run(args)
+		if _, ok := err.(*ExitError); !ok {
+			fmt.Fprintln(res.set.stderr(), err)
+		}
+		res.set.terminate(code)
+		return
+	}
+	if c, ok := res.cont.cmd().(ExitCmd); ok && !res.help {
+		res.set.terminate(c.ExitCode())
 	}
 }
 
+// Runs the runnable of the matched sub-command, or prints its
+// usage if help is asked.
+func (res *parseResult) runErr(ctx context.Context) error {
+	if res.help {
+		res.set.subcommandUsage(res.cont)
+		return nil
+	}
+	if res.set.tracing() {
+		res.set.printTrace(res.set.stderr(), res)
+	}
+	if err := res.confirmRun(); err != nil {
+		return err
+	}
+	if c, ok := res.cont.cmd().(IOCmd); ok {
+		c.SetIO(res.set.streams(res.cont))
+	}
+	if c, ok := res.cont.cmd().(CmdContext); ok {
+		if err := c.RunContext(ctx, res.args); err != nil {
+			return err
+		}
+	} else if c, ok := res.cont.cmd().(CmdE); ok {
+		if err := c.RunE(res.args); err != nil {
+			return err
+		}
+	} else {
+		res.cont.cmd().Run(res.args)
+	}
+	if c, ok := res.cont.cmd().(ExitCmd); ok && c.ExitCode() != 0 {
+		return &ExitError{Code: c.ExitCode()}
+	}
+	return nil
+}
+
 // Parses flags and run's matching subcommand's runnable.
 func ParseAndRun() {
-	Parse()
-	Run()
+	defaultSet.ParseAndRun(os.Args[1:])
+}
+
+// ParseAndRun parses the arguments and runs the matching
+// subcommand's runnable.
+func (s *CommandSet) ParseAndRun(arguments []string) {
+	if res := s.parseOrExit(arguments); res != nil {
+		res.run(context.Background())
+	}
+}
+
+// ParseAndRunE parses flags and runs the matching subcommand's
+// runnable like ParseAndRun, but returns an error instead of printing
+// the usage and exiting if the arguments don't match the configuration.
+// The runnable is not called if parsing fails.
+func ParseAndRunE() error {
+	return defaultSet.ParseAndRunE(os.Args[1:])
+}
+
+// ParseAndRunE parses the arguments and runs the matching
+// subcommand's runnable like the package-level ParseAndRunE.
+func (s *CommandSet) ParseAndRunE(arguments []string) error {
+	return s.ParseAndRunContext(context.Background(), arguments)
+}
+
+// ParseAndRunContext parses flags and runs the matching subcommand's
+// runnable like ParseAndRunE, passing ctx to it if the subcommand
+// implements CmdContext.
+func ParseAndRunContext(ctx context.Context) error {
+	return defaultSet.ParseAndRunContext(ctx, os.Args[1:])
 }
 
-// Returns the total number of globally registered flags.
-func numOfGlobalFlags() (count int) {
-	flag.VisitAll(func(flag *flag.Flag) {
-		count++
+// ParseAndRunContext parses the arguments and runs the matching
+// subcommand's runnable like the package-level ParseAndRunContext.
+func (s *CommandSet) ParseAndRunContext(ctx context.Context, arguments []string) error {
+	s.mu.Lock()
+	res, err := s.parse(arguments, flag.ContinueOnError)
+	if err != nil {
+		res = nil
+	}
+	s.matching = res
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		return res.runErr(ctx)
+	}
+	return nil
+}
+
+// Returns the total number of globally registered flags, hidden
+// flags excluded.
+func (s *CommandSet) numOfGlobalFlags() (count int) {
+	s.Flags().VisitAll(func(f *flag.Flag) {
+		if !isHiddenFlag(s.Flags(), f) {
+			count++
+		}
 	})
 	return
 }
@@ -15,8 +15,16 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -51,9 +59,9 @@ func TestGlobalFlagsCount(t *testing.T) {
 	flag.String("global2", "default-global2", "Description about global2")
 	Parse()
 
-	total := numOfGlobalFlags()
+	total := defaultSet.numOfGlobalFlags()
 	if total != 2 {
-		t.Error("total number of global flags are expected to be 2, found %v", total)
+		t.Errorf("total number of global flags are expected to be 2, found %v", total)
 	}
 }
 
@@ -131,15 +139,484 @@ func TestAdditionalCommandArgs(t *testing.T) {
 	c1 := &testCmd1{}
 	On("command1", "", c1, []string{})
 	Parse()
+	args := parsedArgs()
 	if len(args) < 1 || args[0] != "somearg" {
 		t.Error("additional command 'somearg' is expected, but can't be found")
 	}
 }
 
-// Resets os.Args and the default flag set.
+// Tests if "--" preceding the command terminates global flags.
+func TestTerminatorBeforeCommand(t *testing.T) {
+	resetForTesting("-global1=hello", "--", "command1", "-flag1=true")
+
+	flagGlobal1 := flag.String("global1", "default-global1", "Description about global1")
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{})
+	Parse()
+	Run()
+	if !c1.run {
+		t.Error("command 'command1' was expected to run, but it didn't")
+	}
+	if *flagGlobal1 != "hello" {
+		t.Errorf("global flag should be set: expected hello, found %s", *flagGlobal1)
+	}
+	if !*c1.flag1 {
+		t.Errorf("flag1 should be set: expected true, found %v", *c1.flag1)
+	}
+}
+
+// Tests if "--" following the command terminates command flags.
+func TestTerminatorAfterCommand(t *testing.T) {
+	resetForTesting("command1", "--", "-flag1=true", "somearg")
+
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{})
+	Parse()
+	if *c1.flag1 {
+		t.Errorf("flag1 should not be set: expected false, found %v", *c1.flag1)
+	}
+	if args := parsedArgs(); len(args) != 2 || args[0] != "-flag1=true" || args[1] != "somearg" {
+		t.Errorf("expected args [-flag1=true somearg], found %v", args)
+	}
+}
+
+// Tests if "--" following a pass-through command is dropped.
+func TestTerminatorDisableFlagParsing(t *testing.T) {
+	resetForTesting("command1", "--", "-v", "--")
+
+	On("command1", "", &testCmd1{}, []string{}, DisableFlagParsing())
+	Parse()
+	if args := parsedArgs(); len(args) != 2 || args[0] != "-v" || args[1] != "--" {
+		t.Errorf("expected args [-v --], found %v", args)
+	}
+}
+
+// Tests if arguments are passed verbatim to a sub-command
+// that disables flag parsing.
+func TestDisableFlagParsing(t *testing.T) {
+	resetForTesting("command1", "-flag1=true", "-v", "somearg")
+
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{"flag1"}, DisableFlagParsing())
+	Parse()
+	Run()
+	if !c1.run {
+		t.Error("command 'command1' was expected to run, but it didn't")
+	}
+	if c1.flag1 != nil {
+		t.Error("flags of command 'command1' were not expected to be defined")
+	}
+	expected := []string{"-flag1=true", "-v", "somearg"}
+	args := parsedArgs()
+	if len(args) != len(expected) {
+		t.Fatalf("expected args %v, found %v", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("expected args %v, found %v", expected, args)
+		}
+	}
+}
+
+// Tests if command sets keep their own commands and global flags.
+func TestCommandSet(t *testing.T) {
+	resetForTesting()
+
+	set1, set2 := New("tool1"), New("tool2")
+	verbose := set1.Flags().Bool("v", false, "Description about v")
+	c1, c2 := &testCmd1{}, &testCmd1{}
+	set1.On("command1", "", c1, []string{})
+	set2.On("command1", "", c2, []string{"flag1"})
+	if err := set1.ParseAndRunE([]string{"-v", "command1", "somearg"}); err != nil {
+		t.Fatal(err)
+	}
+	if !*verbose || !c1.run {
+		t.Error("command 'command1' of set1 was expected to run with -v")
+	}
+	if c2.run {
+		t.Error("command 'command1' of set2 was not expected to run")
+	}
+	if err := set2.ParseAndRunE([]string{"command1"}); err == nil {
+		t.Error("an error was expected for the required flag of set2")
+	}
+	if len(defaultSet.cmds) != 0 {
+		t.Error("the default set was not expected to have commands")
+	}
+}
+
+// Tests if Parse returns or panics instead of exiting if the set is
+// created with ContinueOnError or PanicOnError.
+func TestErrorHandling(t *testing.T) {
+	resetForTesting()
+	defer func(f func(int)) { exit = f }(exit)
+	exit = func(code int) { t.Errorf("unexpected exit with %d", code) }
+
+	set := NewWithErrorHandling("tool", flag.ContinueOnError)
+	c1 := &testCmd1{}
+	set.On("command1", "", c1, []string{"flag1"})
+	captureStderr(func() { set.ParseAndRun([]string{"command1"}) })
+	if c1.run {
+		t.Error("command 'command1' was not expected to run")
+	}
+	captureStderr(func() { set.Parse([]string{"command2"}) })
+	set.Run()
+
+	set = NewWithErrorHandling("tool", flag.PanicOnError)
+	set.On("command1", "", &testCmd1{}, nil)
+	defer func() {
+		if _, ok := recover().(*UnknownCommandError); !ok {
+			t.Error("expected a panic with an UnknownCommandError")
+		}
+	}()
+	captureStderr(func() { set.Parse([]string{"command2"}) })
+}
+
+// Tests if exits are routed through the exit function of the set.
+func TestSetExit(t *testing.T) {
+	var codes []int
+	set := New("tool")
+	set.SetExit(func(code int) { codes = append(codes, code) })
+	set.On("exit", "", &exitCmd{code: 3}, nil)
+	captureStderr(func() {
+		set.ParseAndRun([]string{"exit"})
+		set.ParseAndRun([]string{"unknown"})
+		set.ParseAndRun([]string{"-undefined"})
+		set.ParseAndRun([]string{"exit", "-undefined"})
+	})
+	if !reflect.DeepEqual(codes, []int{3, 1, 2, 2}) {
+		t.Errorf("expected exit codes [3 1 2 2], found %v", codes)
+	}
+}
+
+// Tests if sub-commands can be deregistered and replaced.
+func TestOffReplace(t *testing.T) {
+	resetForTesting("command1", "-flag1")
+
+	On("command1", "", &testCmd1{}, []string{"flag1"})
+	On("command2", "", &testCmd2{}, []string{})
+	if !Off("command2") || Off("command2") {
+		t.Error("command 'command2' was expected to be deregistered once")
+	}
+	if _, ok := Lookup("command2"); ok {
+		t.Error("command 'command2' was not expected to be found")
+	}
+	c1 := &testCmd1{}
+	if !Replace("command1", c1) || Replace("command2", c1) {
+		t.Error("only command 'command1' was expected to be replaced")
+	}
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run {
+		t.Error("the replacement of 'command1' was expected to run")
+	}
+}
+
+// Tests if sub-commands can be registered and parsed concurrently.
+func TestConcurrentRegistration(t *testing.T) {
+	set := New("tool")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			set.On(fmt.Sprintf("command%d", i), "", &testCmd2{}, nil)
+			set.ParseErr([]string{"command0"})
+			set.Lookup("command0")
+		}(i)
+	}
+	wg.Wait()
+	if n := len(set.Commands()); n != 10 {
+		t.Errorf("expected 10 commands, found %d", n)
+	}
+}
+
+// Tests if ParseAndRunE returns parse errors without running.
+func TestParseAndRunE(t *testing.T) {
+	resetForTesting("command2")
+
+	On("command1", "", &testCmd1{}, []string{})
+	if err := ParseAndRunE(); err == nil {
+		t.Error("an error was expected for an unknown command")
+	}
+
+	resetForTesting("command1")
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{"flag1"})
+	if err := ParseAndRunE(); err == nil {
+		t.Error("an error was expected for a missing required flag")
+	}
+	if c1.run {
+		t.Error("command 'command1' was not expected to run")
+	}
+
+	resetForTesting("command1", "-flag1")
+	c1 = &testCmd1{}
+	On("command1", "", c1, []string{"flag1"})
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run {
+		t.Error("command 'command1' was expected to run, but it didn't")
+	}
+}
+
+// Tests if ParseErr and RunErr return errors instead of exiting.
+func TestParseErrRunErr(t *testing.T) {
+	resetForTesting()
+	On("command1", "", &testCmd1{}, []string{})
+	if err := ParseErr(); err != ErrNoCommand {
+		t.Errorf("expected ErrNoCommand, found %v", err)
+	}
+
+	resetForTesting("command2")
+	On("command1", "", &testCmd1{}, []string{})
+	if err, ok := ParseErr().(*UnknownCommandError); !ok || err.Name != "command2" {
+		t.Errorf("expected an UnknownCommandError for command2, found %v", err)
+	}
+
+	resetForTesting("exit")
+	On("exit", "", &exitCmd{code: 3}, []string{})
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if err, ok := RunErr().(*ExitError); !ok || err.Code != 3 {
+		t.Errorf("expected an ExitError with code 3, found %v", err)
+	}
+}
+
+// Tests if RunErr doesn't run a sub-command failing the validation.
+func TestParseErrValidationRunErr(t *testing.T) {
+	resetForTesting("command1")
+
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{"flag1"})
+	if _, ok := ParseErr().(Errors); !ok {
+		t.Error("an error was expected for the missing required flag")
+	}
+	if err := RunErr(); err != nil {
+		t.Fatal(err)
+	}
+	if c1.run {
+		t.Error("command 'command1' was not expected to run")
+	}
+}
+
+// Tests if the context is passed to the command and its error returned.
+func TestRunContext(t *testing.T) {
+	resetForTesting("ctx", "somearg")
+
+	cmd := &contextCmd{err: errors.New("failed")}
+	On("ctx", "", cmd, []string{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ParseAndRunContext(ctx); err != cmd.err {
+		t.Errorf("expected error %v, found %v", cmd.err, err)
+	}
+	if cmd.ctx != ctx {
+		t.Error("context was expected to be passed to the command")
+	}
+	if cmd.run {
+		t.Error("Run was not expected to be called")
+	}
+}
+
+// Tests if the error of a CmdE is returned, and its ExitCoder code
+// used by ParseAndRun.
+func TestCmdE(t *testing.T) {
+	resetForTesting("cmde")
+	defer func(f func(int)) { exit = f }(exit)
+	code := -1
+	exit = func(c int) { code = c }
+
+	cmd := &errCmd{err: errors.New("failed")}
+	On("cmde", "", cmd, []string{})
+	if err := ParseAndRunE(); err != cmd.err {
+		t.Errorf("expected error %v, found %v", cmd.err, err)
+	}
+	if cmd.run {
+		t.Error("Run was not expected to be called")
+	}
+	if out := captureStderr(ParseAndRun); out != "failed\n" || code != 1 {
+		t.Errorf("expected the error printed and exit code 1, found %q and %d", out, code)
+	}
+	cmd.err = &exitCodeErr{code: 4}
+	captureStderr(ParseAndRun)
+	if code != 4 {
+		t.Errorf("expected exit code 4, found %d", code)
+	}
+}
+
+// Tests if command flags can depend on the parsed global flags.
+func TestGlobalFlagsCmd(t *testing.T) {
+	resetForTesting("-backend=s3", "upload", "-bucket=b1")
+
+	flag.String("backend", "local", "Description about backend")
+	cmd := &backendCmd{}
+	On("upload", "", cmd, []string{})
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.bucket == nil || *cmd.bucket != "b1" {
+		t.Error("bucket flag was expected to be defined and set")
+	}
+
+	resetForTesting("upload", "-bucket=b1")
+	flag.String("backend", "local", "Description about backend")
+	cmd = &backendCmd{}
+	On("upload", "", cmd, []string{})
+	if err := ParseAndRunE(); err == nil {
+		t.Error("an error was expected for the undefined bucket flag")
+	}
+}
+
+// Tests if the process exits with the code chosen by the command.
+func TestExitCmd(t *testing.T) {
+	resetForTesting("exit")
+	defer func() { exit = os.Exit }()
+
+	code := -1
+	exit = func(c int) { code = c }
+	On("exit", "", &exitCmd{code: 3}, []string{})
+	Parse()
+	Run()
+	if code != 3 {
+		t.Errorf("expected exit code 3, found %v", code)
+	}
+}
+
+// Tests if -h before the command prints the usage and exits with 0.
+func TestGlobalHelp(t *testing.T) {
+	resetForTesting("-h")
+	defer func() { exit = os.Exit }()
+
+	code := -1
+	exit = func(c int) { code = c }
+	On("command1", "Description about command1", &testCmd1{}, []string{})
+	out := captureStderr(Parse)
+	if code != 0 {
+		t.Errorf("expected exit code 0, found %v", code)
+	}
+	if !strings.Contains(out, "where <command> is one of:") || !strings.Contains(out, "Description about command1") {
+		t.Errorf("usage was expected, found %q", out)
+	}
+}
+
+// Tests if -h after the command prints the command usage without exiting.
+func TestCommandHelp(t *testing.T) {
+	resetForTesting("command1", "-h")
+	defer func() { exit = os.Exit }()
+
+	code := -1
+	exit = func(c int) { code = c }
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{"flag1"})
+	out := captureStderr(ParseAndRun)
+	if code != -1 {
+		t.Errorf("exit was not expected, found exit code %v", code)
+	}
+	if c1.run {
+		t.Error("command 'command1' was not expected to run")
+	}
+	if !strings.Contains(out, "Usage of cmd command1:") || !strings.Contains(out, "-flag1") {
+		t.Errorf("command usage was expected, found %q", out)
+	}
+}
+
+// Tests if the usage is printed with the customized text.
+func TestUsageStrings(t *testing.T) {
+	resetForTesting()
+	defer func(text UsageText) { UsageStrings = text }(UsageStrings)
+
+	UsageStrings.Commands = "Befehle:"
+	UsageStrings.Help = "%s <Befehl> -h zeigt die Hilfe"
+	On("command1", "", &testCmd1{}, []string{})
+	out := captureStderr(Usage)
+	if !strings.Contains(out, "Befehle:\n") {
+		t.Errorf("usage was expected to contain the commands heading, found %q", out)
+	}
+	if !strings.Contains(out, "cmd <Befehl> -h zeigt die Hilfe") {
+		t.Errorf("usage was expected to contain the help text, found %q", out)
+	}
+}
+
+// Tests if the usage is rendered into a string.
+func TestUsageString(t *testing.T) {
+	resetForTesting()
+
+	flag.String("global1", "default-global1", "Description about global1")
+	On("command1", "Description about command1", &testCmd1{}, []string{"flag1"})
+	usage := UsageString()
+	for _, s := range []string{"command1", "Description about command1", "-global1"} {
+		if !strings.Contains(usage, s) {
+			t.Errorf("usage was expected to contain %q, found %q", s, usage)
+		}
+	}
+	usage, err := SubcommandUsageString("command1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Usage of cmd command1:", "-flag1", "Description about flag1", "flag1\n"} {
+		if !strings.Contains(usage, s) {
+			t.Errorf("subcommand usage was expected to contain %q, found %q", s, usage)
+		}
+	}
+	if _, err := SubcommandUsageString("command2"); err == nil {
+		t.Error("an error was expected for an unknown command")
+	}
+}
+
+// Tests if the command help is rendered without parsing.
+func TestPrintCommandHelp(t *testing.T) {
+	resetForTesting()
+
+	On("command1", "", &testCmd1{}, []string{})
+	On("command2", "", &testCmd2{}, []string{})
+	var buf bytes.Buffer
+	if err := PrintCommandHelp("command2", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "-flag2") || strings.Contains(buf.String(), "-flag1") {
+		t.Errorf("help of command2 was expected, found %q", buf.String())
+	}
+	if err := PrintCommandHelp("command3", &buf); err == nil {
+		t.Error("an error was expected for an unknown command")
+	}
+}
+
+// Resets os.Args, the default flag set and the registered commands.
 func resetForTesting(args ...string) {
 	os.Args = append([]string{"cmd"}, args...)
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	// as the default flag set, defer to flag.Usage.
+	flag.CommandLine.Usage = func() { flag.Usage() }
+	defaultSet = newCommandSet()
+}
+
+// Returns the arguments of the sub-command matched by Parse.
+func parsedArgs() []string {
+	if defaultSet.matching == nil {
+		return nil
+	}
+	return defaultSet.matching.args
+}
+
+// Returns what f writes to os.Stderr.
+func captureStderr(f func()) string {
+	tmp, err := ioutil.TempFile("", "stderr")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(tmp.Name())
+	defer func(stderr *os.File) { os.Stderr = stderr }(os.Stderr)
+	os.Stderr = tmp
+	f()
+	tmp.Close()
+	b, err := ioutil.ReadFile(tmp.Name())
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
 }
 
 // testCmd1 is a test sub command.
@@ -160,6 +637,63 @@ func (cmd *testCmd1) Run(args []string) {
 	cmd.run = true
 }
 
+// backendCmd is a test sub command defining a flag for the s3 backend.
+type backendCmd struct {
+	testCmd1
+
+	bucket *string
+}
+
+func (cmd *backendCmd) FlagsWithGlobals(fs *flag.FlagSet, globals *flag.FlagSet) *flag.FlagSet {
+	if globals.Lookup("backend").Value.String() == "s3" {
+		cmd.bucket = fs.String("bucket", "", "Description about bucket")
+	}
+	return fs
+}
+
+// contextCmd is a test sub command receiving a context.
+type contextCmd struct {
+	testCmd1
+
+	ctx context.Context
+	err error
+}
+
+func (cmd *contextCmd) RunContext(ctx context.Context, args []string) error {
+	cmd.ctx = ctx
+	return cmd.err
+}
+
+// errCmd is a test sub command failing with an error.
+type errCmd struct {
+	testCmd1
+
+	err error
+}
+
+func (cmd *errCmd) RunE(args []string) error {
+	return cmd.err
+}
+
+// exitCodeErr is a test error choosing the exit code.
+type exitCodeErr struct {
+	code int
+}
+
+func (e *exitCodeErr) Error() string { return "exit code error" }
+func (e *exitCodeErr) ExitCode() int { return e.code }
+
+// exitCmd is a test sub command choosing its exit code.
+type exitCmd struct {
+	testCmd1
+
+	code int
+}
+
+func (cmd *exitCmd) ExitCode() int {
+	return cmd.code
+}
+
 // testCmd2 is a test sub command.
 type testCmd2 struct {
 	flag2 *bool
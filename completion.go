@@ -0,0 +1,358 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Name of the hidden sub-command serving completions.
+const completeCmdName = "__complete"
+
+// EnableCompletion registers a hidden __complete sub-command that
+// prints the completion candidates for the words following it, the
+// last word being the one to complete. Sub-command names, sub-command
+// flags and, for commands implementing Completer, positional arguments
+// are completed. Call it before Parse.
+//
+// If the program is run by bash's `complete -C` with the COMP_LINE
+// and COMP_POINT environment variables set, it serves the completion
+// of the line up to the cursor without the __complete sub-command.
+func EnableCompletion() {
+	defaultSet.EnableCompletion()
+}
+
+// EnableCompletion registers a hidden __complete sub-command in the set.
+func (s *CommandSet) EnableCompletion() {
+	s.On(completeCmdName, "", &completeCmd{set: s}, nil, DisableFlagParsing(), Hidden())
+}
+
+// Number of the sets serving completions, they may be served by
+// several sets concurrently.
+var inCompletion int32
+
+// InCompletion reports whether the program is serving completions.
+// Sub-commands can check it to skip side effects in Flags, which is
+// called to complete their flags.
+func InCompletion() bool {
+	return atomic.LoadInt32(&inCompletion) > 0
+}
+
+// completeCmd prints the completion candidates.
+type completeCmd struct {
+	set *CommandSet
+}
+
+func (cmd *completeCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	return fs
+}
+
+func (cmd *completeCmd) Run(args []string) {
+	atomic.AddInt32(&inCompletion, 1)
+	defer atomic.AddInt32(&inCompletion, -1)
+	for _, candidate := range cmd.set.complete(args) {
+		fmt.Fprintln(cmd.set.stdout(), candidate)
+	}
+}
+
+// Returns the __complete sub-command and the words of the command
+// line to complete, if completions are requested through COMP_LINE.
+func (s *CommandSet) compLineCmd() (*cmdCont, []string, bool) {
+	cont, ok := s.cmds[completeCmdName]
+	line := os.Getenv("COMP_LINE")
+	if !ok || line == "" {
+		return nil, nil, false
+	}
+	if point, err := strconv.Atoi(os.Getenv("COMP_POINT")); err == nil && point >= 0 && point <= len(line) {
+		line = line[:point]
+	}
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return nil, nil, false
+	}
+	// the program name is not completed, an empty word is if the
+	// cursor follows a space.
+	words = words[1:]
+	if strings.HasSuffix(line, " ") || len(words) == 0 {
+		words = append(words, "")
+	}
+	return cont, words, true
+}
+
+// Returns the completion candidates for the last of the words.
+func (s *CommandSet) complete(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	// skip the global flags preceding the sub-command name.
+	i := 0
+	for i < len(words)-1 && strings.HasPrefix(words[i], "-") {
+		i++
+	}
+	if i == len(words)-1 {
+		c := NewCompletion(words[i:], true)
+		var names []string
+		for name, cont := range s.cmds {
+			if !cont.hidden {
+				names = append(names, name)
+			}
+		}
+		matches := c.Match(names...)
+		sort.Strings(matches)
+		return matches
+	}
+	cont, ok := s.cmds[words[i]]
+	if !ok || cont.hidden {
+		return nil
+	}
+	if n, ok := cont.cmd().(*nestedCmd); ok {
+		return n.set.complete(words[i+1:])
+	}
+	c := NewCompletion(words[i+1:], true)
+	if !cont.disableFlagParsing {
+		c.fs = s.flagSet(cont, flag.ContinueOnError)
+	}
+	if fs := c.fs; fs != nil && !c.terminated() {
+		if values, ok := completeFlagValue(fs, c); ok {
+			return values
+		}
+		if strings.HasPrefix(c.Prefix(), "-") {
+			var names []string
+			fs.VisitAll(func(f *flag.Flag) {
+				if !isHiddenFlag(fs, f) {
+					names = append(names, "-"+f.Name)
+				}
+			})
+			return c.Match(names...)
+		}
+	}
+	if completer, ok := cont.cmd().(Completer); ok {
+		return completer.Complete(c)
+	}
+	// declared positionals are completed as file paths, a variadic
+	// one takes any number of positions.
+	if c.Position() < len(cont.args) || cont.variadicIndex() >= 0 {
+		return c.Files()
+	}
+	return nil
+}
+
+// ValueCompleter is implemented by flag values that complete their
+// own values, e.g. the allowed values of a Choice flag. They are
+// completed following the flag, as in -format j or -format=j. See
+// CompleteFlag and RegisterTypeCompleter to complete other flags.
+type ValueCompleter interface {
+	flag.Value
+	CompleteValue(prefix string) []string
+}
+
+// Returns the completions of the value of a flag of fs if the word
+// being completed is one.
+func completeFlagValue(fs *flag.FlagSet, c *Completion) ([]string, bool) {
+	if strings.HasPrefix(c.Prefix(), "-") {
+		i := strings.Index(c.Prefix(), "=")
+		if i < 0 {
+			return nil, false
+		}
+		f := fs.Lookup(strings.TrimLeft(c.Prefix()[:i], "-"))
+		if f == nil {
+			return nil, false
+		}
+		complete := valueCompleter(f)
+		if complete == nil {
+			return nil, false
+		}
+		var values []string
+		for _, v := range complete(c.Prefix()[i+1:]) {
+			values = append(values, c.Prefix()[:i+1]+v)
+		}
+		return values, true
+	}
+	if len(c.args) == 0 {
+		return nil, false
+	}
+	prev := c.args[len(c.args)-1]
+	if !strings.HasPrefix(prev, "-") || strings.Contains(prev, "=") {
+		return nil, false
+	}
+	f := fs.Lookup(strings.TrimLeft(prev, "-"))
+	if f == nil || isBoolFlag(f) {
+		return nil, false
+	}
+	if complete := valueCompleter(f); complete != nil {
+		return complete(c.Prefix()), true
+	}
+	return nil, false
+}
+
+// CompleteFlag sets the function completing the values of the flag
+// name of fs, e.g. the regions of a -region flag. It takes precedence
+// over the completion of its value. It panics if name is not defined.
+func CompleteFlag(fs *flag.FlagSet, name string, complete func(prefix string) []string) {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("command: completion of undefined flag -%s", name))
+	}
+	f.Value = &completedValue{valueWrapper: valueWrapper{f.Value}, complete: complete}
+}
+
+// completedValue is the value of a flag completed by CompleteFlag.
+type completedValue struct {
+	valueWrapper
+	complete func(prefix string) []string
+}
+
+func (v *completedValue) CompleteValue(prefix string) []string {
+	return v.complete(prefix)
+}
+
+// Functions completing the values of flags by the type of their
+// values.
+var (
+	typeCompletersMu sync.Mutex
+	typeCompleters   = make(map[reflect.Type]func(prefix string) []string)
+)
+
+// RegisterTypeCompleter sets the function completing the values of
+// the flags whose values have the type of value, e.g. a custom
+// flag.Value of a third-party package which doesn't implement
+// ValueCompleter. Flags completed by CompleteFlag or whose values
+// implement ValueCompleter aren't completed by it.
+func RegisterTypeCompleter(value flag.Value, complete func(prefix string) []string) {
+	typeCompletersMu.Lock()
+	defer typeCompletersMu.Unlock()
+	typeCompleters[reflect.TypeOf(value)] = complete
+}
+
+// Returns the function completing the values of the flag, nil if
+// they aren't completed.
+func valueCompleter(f *flag.Flag) func(prefix string) []string {
+	var vc ValueCompleter
+	wrapsValue(f.Value, func(v flag.Value) bool {
+		vc, _ = v.(ValueCompleter)
+		return vc != nil
+	})
+	if vc != nil {
+		return vc.CompleteValue
+	}
+	typeCompletersMu.Lock()
+	defer typeCompletersMu.Unlock()
+	return typeCompleters[reflect.TypeOf(unwrapValue(f.Value))]
+}
+
+// Completer is implemented by sub-commands that complete their own
+// positional arguments.
+type Completer interface {
+	Complete(c *Completion) []string
+}
+
+// Completion describes the word being completed for a sub-command.
+type Completion struct {
+	args   []string
+	prefix string
+	// flags of the sub-command, nil if they aren't parsed.
+	fs *flag.FlagSet
+}
+
+// NewCompletion resolves the completion state from the words following
+// the sub-command name. If inword is true, the cursor is in the middle
+// of the last word and it is treated as the prefix to complete,
+// otherwise a new word is being started and the prefix is empty.
+func NewCompletion(words []string, inword bool) *Completion {
+	c := &Completion{args: words}
+	if inword && len(words) > 0 {
+		c.args = words[:len(words)-1]
+		c.prefix = words[len(words)-1]
+	}
+	return c
+}
+
+// Args returns the completed words preceding the one being completed.
+func (c *Completion) Args() []string {
+	return c.args
+}
+
+// Prefix returns the partially typed word being completed.
+func (c *Completion) Prefix() string {
+	return c.prefix
+}
+
+// Position returns the zero-based index of the positional argument
+// being completed. Flags and the values following them are not
+// counted as positional arguments, unless they follow a "--"
+// terminator. The values are only told apart for the flags of the
+// sub-command being completed, not by a NewCompletion.
+func (c *Completion) Position() (pos int) {
+	terminated, value := false, false
+	for _, arg := range c.args {
+		switch {
+		case value:
+			value = false
+		case terminated || !strings.HasPrefix(arg, "-"):
+			pos++
+		case arg == "--":
+			terminated = true
+		default:
+			value = c.takesValue(arg)
+		}
+	}
+	return
+}
+
+// Reports whether the flag arg is followed by its value, a flag of
+// the sub-command which isn't a bool flag and has no "=" value.
+func (c *Completion) takesValue(arg string) bool {
+	if c.fs == nil || strings.Contains(arg, "=") {
+		return false
+	}
+	f := c.fs.Lookup(strings.TrimLeft(arg, "-"))
+	return f != nil && !isBoolFlag(f)
+}
+
+// Reports whether the completed words contain a "--" terminator.
+func (c *Completion) terminated() bool {
+	for _, arg := range c.args {
+		if arg == "--" {
+			return true
+		}
+	}
+	return false
+}
+
+// Files returns the file paths starting with the prefix.
+func (c *Completion) Files() []string {
+	matches, _ := filepath.Glob(c.prefix + "*")
+	return matches
+}
+
+// Match returns the candidates starting with the prefix.
+func (c *Completion) Match(candidates ...string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, c.prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,280 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Tests the position and prefix of a word being typed.
+func TestCompletionInWord(t *testing.T) {
+	c := NewCompletion([]string{"-v", "src", "ds"}, true)
+	if c.Position() != 1 {
+		t.Errorf("expected position 1, found %v", c.Position())
+	}
+	if c.Prefix() != "ds" {
+		t.Errorf("expected prefix ds, found %v", c.Prefix())
+	}
+}
+
+// Tests the position and prefix of a new word.
+func TestCompletionNewWord(t *testing.T) {
+	c := NewCompletion([]string{"-v", "src", "dst"}, false)
+	if c.Position() != 2 {
+		t.Errorf("expected position 2, found %v", c.Position())
+	}
+	if c.Prefix() != "" {
+		t.Errorf("expected empty prefix, found %v", c.Prefix())
+	}
+}
+
+// Tests if words following a "--" are counted as positionals.
+func TestCompletionTerminator(t *testing.T) {
+	c := NewCompletion([]string{"-v", "--", "-src", ""}, true)
+	if c.Position() != 1 {
+		t.Errorf("expected position 1, found %v", c.Position())
+	}
+}
+
+// Tests if sub-command names are completed.
+func TestCompleteCommands(t *testing.T) {
+	resetForTesting()
+	EnableCompletion()
+	On("command1", "", &testCmd1{}, []string{})
+	On("command2", "", &testCmd2{}, []string{})
+	On("other", "", &testCmd2{}, []string{})
+	if found := fmt.Sprint(defaultSet.complete([]string{"-global1=x", "com"})); found != "[command1 command2]" {
+		t.Errorf("expected [command1 command2], found %v", found)
+	}
+}
+
+// Tests if sub-command flags and positionals are completed.
+func TestCompleteCommandArgs(t *testing.T) {
+	resetForTesting()
+	EnableCompletion()
+	On("cp", "", &copyCmd{}, []string{})
+	if found := fmt.Sprint(defaultSet.complete([]string{"cp", "-fl"})); found != "[-flag1]" {
+		t.Errorf("expected [-flag1], found %v", found)
+	}
+	if found := fmt.Sprint(defaultSet.complete([]string{"cp", "-flag1", "local", ""})); found != "[backup bucket]" {
+		t.Errorf("expected [backup bucket], found %v", found)
+	}
+}
+
+// Tests if completion is reported active while it's served.
+func TestInCompletion(t *testing.T) {
+	resetForTesting(completeCmdName, "probe", "-")
+
+	cmd := &probeCmd{}
+	EnableCompletion()
+	On("probe", "", cmd, []string{})
+	Parse()
+	Run()
+	if !cmd.inCompletion {
+		t.Error("completion was expected to be active in Flags")
+	}
+	if InCompletion() {
+		t.Error("completion was not expected to be active after it's served")
+	}
+}
+
+// Tests if completions are served by sets concurrently.
+func TestInCompletionConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		set := New("tool")
+		set.SetIO(nil, ioutil.Discard, ioutil.Discard)
+		set.EnableCompletion()
+		set.On("probe", "", &probeCmd{}, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				set.ParseAndRunE([]string{completeCmdName, "probe", "-"})
+			}
+		}()
+	}
+	wg.Wait()
+	if InCompletion() {
+		t.Error("completion was not expected to be active after it's served")
+	}
+}
+
+// probeCmd records whether completion is active in Flags.
+type probeCmd struct {
+	testCmd1
+
+	inCompletion bool
+}
+
+func (cmd *probeCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	cmd.inCompletion = InCompletion()
+	return cmd.testCmd1.Flags(fs)
+}
+
+// copyCmd completes its source and destination arguments.
+type copyCmd struct{ testCmd1 }
+
+func (cmd *copyCmd) Complete(c *Completion) []string {
+	switch c.Position() {
+	case 0:
+		return c.Match("local", "remote")
+	case 1:
+		return c.Match("backup", "bucket")
+	}
+	return nil
+}
+
+// regionCopyCmd is a copyCmd with a -region flag.
+type regionCopyCmd struct{ copyCmd }
+
+func (cmd *regionCopyCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	fs.String("region", "", "")
+	return cmd.copyCmd.Flags(fs)
+}
+
+// Tests if the values of flags aren't counted as positionals.
+func TestCompletionFlagValues(t *testing.T) {
+	resetForTesting()
+	On("copy", "", &regionCopyCmd{}, nil)
+	for _, tt := range []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"copy", "-region", "us", "l"}, []string{"local"}},
+		{[]string{"copy", "-region=us", "-flag1", "l"}, []string{"local"}},
+		{[]string{"copy", "-flag1", "local", "b"}, []string{"backup", "bucket"}},
+	} {
+		if found := defaultSet.complete(tt.words); !reflect.DeepEqual(found, tt.want) {
+			t.Errorf("%v: expected %v, found %v", tt.words, tt.want, found)
+		}
+	}
+}
+
+func ExampleCompleter() {
+	var cmd Completer = &copyCmd{}
+	fmt.Println(cmd.Complete(NewCompletion([]string{"local", "b"}, true)))
+	// Output: [backup bucket]
+}
+
+// Tests if the command line to complete is read from COMP_LINE
+// and COMP_POINT.
+func TestCompLine(t *testing.T) {
+	resetForTesting("command1", "co", "program")
+	defer os.Unsetenv("COMP_LINE")
+	defer os.Unsetenv("COMP_POINT")
+
+	EnableCompletion()
+	On("command1", "", &testCmd1{}, []string{})
+	os.Setenv("COMP_LINE", "program command1 -fl trailing")
+	os.Setenv("COMP_POINT", "20")
+	_, words, ok := defaultSet.compLineCmd()
+	if !ok || !reflect.DeepEqual(words, []string{"command1", "-fl"}) {
+		t.Errorf("unexpected words: %v", words)
+	}
+	os.Setenv("COMP_LINE", "program command1 ")
+	os.Unsetenv("COMP_POINT")
+	if _, words, _ = defaultSet.compLineCmd(); !reflect.DeepEqual(words, []string{"command1", ""}) {
+		t.Errorf("unexpected words: %v", words)
+	}
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if args := parsedArgs(); !reflect.DeepEqual(args, []string{"command1", ""}) {
+		t.Errorf("expected the completion to be served, found args %v", args)
+	}
+}
+
+// formatCmd is a test sub command with a choice flag.
+type formatCmd struct {
+	testCmd1
+}
+
+func (cmd *formatCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	Choice(fs, "format", []string{"json", "yaml", "table"}, "table", "")
+	return fs
+}
+
+// Tests if the values of a choice flag are completed.
+func TestCompleteChoice(t *testing.T) {
+	resetForTesting()
+	On("print", "", &formatCmd{}, nil)
+	for _, tt := range []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"print", "-format", "j"}, []string{"json"}},
+		{[]string{"print", "-format", ""}, []string{"json", "yaml", "table"}},
+		{[]string{"print", "-format=t"}, []string{"-format=table"}},
+	} {
+		if found := defaultSet.complete(tt.words); !reflect.DeepEqual(found, tt.want) {
+			t.Errorf("%v: expected %v, found %v", tt.words, tt.want, found)
+		}
+	}
+}
+
+// Tests if flags are completed by the functions registered for them
+// or for the type of their values.
+func TestCompleteFlag(t *testing.T) {
+	resetForTesting()
+	RegisterTypeCompleter(new(levelValue), func(prefix string) []string {
+		return []string{prefix + "1", prefix + "2"}
+	})
+	On("deploy", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.String("region", "", "")
+		fs.Var(new(levelValue), "level", "")
+		Choice(fs, "format", []string{"json", "text"}, "text", "")
+		CompleteFlag(fs, "region", func(prefix string) []string {
+			return (&Completion{prefix: prefix}).Match("eu-west-1", "us-east-1")
+		})
+		GroupFlags(fs, "output options", "format")
+	}}, nil)
+	for _, tt := range []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"deploy", "-region", "u"}, []string{"us-east-1"}},
+		{[]string{"deploy", "-region=e"}, []string{"-region=eu-west-1"}},
+		{[]string{"deploy", "-level", "x"}, []string{"x1", "x2"}},
+		{[]string{"deploy", "-format", "j"}, []string{"json"}},
+	} {
+		if found := defaultSet.complete(tt.words); !reflect.DeepEqual(found, tt.want) {
+			t.Errorf("%v: expected %v, found %v", tt.words, tt.want, found)
+		}
+	}
+}
+
+// levelValue is a test flag value without completion of its own.
+type levelValue struct {
+	level string
+}
+
+func (v *levelValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.level
+}
+
+func (v *levelValue) Set(s string) error {
+	v.level = s
+	return nil
+}
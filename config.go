@@ -0,0 +1,248 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Name of the flag providing the path of a config file.
+const configFlagName = "config"
+
+// EnableConfigFlag registers a -config flag on every sub-command and
+// as a global flag. If it is set, the named config file is loaded
+// during Parse and its values are set to the flags of the sub-command
+// which are set neither on the command line nor by the environment.
+// The top-level keys apply to every sub-command, a table named by a
+// sub-command applies to it only and takes precedence. Keys that don't
+// match a flag are reported as warnings. Without a -config flag, the
+// file named config in the directory of the program in the user
+// config directory is loaded if it exists, e.g.
+// ~/.config/mytool/config.json on Linux, ~/Library/Application
+// Support/mytool/config.json on macOS or
+// %AppData%\mytool\config.json on Windows.
+//
+//	{"verbose": true, "serve": {"port": 8080, "tags": ["a", "b"]}}
+//
+// Array values set the flag once for each of their elements. JSON
+// files are supported, other formats can be registered with
+// RegisterConfigFormat, e.g. by importing the configformats package
+// for YAML and TOML.
+func EnableConfigFlag() {
+	defaultSet.EnableConfigFlag()
+}
+
+// EnableConfigFlag registers a -config flag on every sub-command
+// of the set.
+func (s *CommandSet) EnableConfigFlag() {
+	s.configFlagEnabled = true
+}
+
+// Decoders of the config file formats by file extension.
+var (
+	configFormatsMu sync.Mutex
+	configFormats   = map[string]func(data []byte, v interface{}) error{
+		".json": decodeJSON,
+	}
+)
+
+// Decodes JSON keeping the numbers as they are written.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// RegisterConfigFormat registers the decoder of the config files
+// with the extension, e.g. ".yaml". The decoder unmarshals the file
+// into a *map[string]interface{}. Files with an unknown extension
+// are decoded as JSON.
+func RegisterConfigFormat(ext string, decode func(data []byte, v interface{}) error) {
+	configFormatsMu.Lock()
+	defer configFormatsMu.Unlock()
+	configFormats[strings.ToLower(ext)] = decode
+}
+
+// Defines the config flag on fs, unless it's already defined.
+func (s *CommandSet) defineConfigFlag(fs *flag.FlagSet) {
+	if s.configFlagEnabled && fs.Lookup(configFlagName) == nil {
+		fs.String(configFlagName, "", "path to a config file providing flag values")
+	}
+}
+
+// Returns the path of the config file, given to the sub-command or
+// as a global flag, or found in the user config directory.
+func (s *CommandSet) configPath(fs *flag.FlagSet) string {
+	for _, set := range []*flag.FlagSet{fs, s.Flags()} {
+		if f := set.Lookup(configFlagName); f != nil && f.Value.String() != "" {
+			return f.Value.String()
+		}
+	}
+	return s.userConfigFile()
+}
+
+// Returns the user config directory, replaced in tests.
+var userConfigDir = os.UserConfigDir
+
+// Returns the first file named config with a registered extension
+// in the directory of the program in the user config directory,
+// e.g. $XDG_CONFIG_HOME/mytool/config.json, or an empty string if
+// there is none.
+func (s *CommandSet) userConfigFile() string {
+	dir, err := userConfigDir()
+	if err != nil {
+		return ""
+	}
+	root := s
+	for root.parent != nil {
+		root = root.parent
+	}
+	configFormatsMu.Lock()
+	exts := make([]string, 0, len(configFormats))
+	for ext := range configFormats {
+		exts = append(exts, ext)
+	}
+	configFormatsMu.Unlock()
+	sort.Strings(exts)
+	for _, ext := range exts {
+		path := filepath.Join(dir, filepath.Base(root.program()), "config"+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// ConfigFile returns the path of the config file loaded by the last
+// Parse, or an empty string if none is loaded.
+func ConfigFile() string {
+	return defaultSet.ConfigFile()
+}
+
+// ConfigFile returns the path of the config file loaded by the last
+// Parse of the set.
+func (s *CommandSet) ConfigFile() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.configFile
+}
+
+// Sets the values of the config file named by the config flag to the
+// flags of fs of the named sub-command which are not set yet, or to
+// the global flags if cmd is empty.
+func (s *CommandSet) applyConfigFile(fs *flag.FlagSet, cmd string) error {
+	if !s.configFlagEnabled {
+		return nil
+	}
+	path := s.configPath(fs)
+	s.configFile = path
+	if path == "" {
+		return nil
+	}
+	raw, err := loadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	values := s.configValues(raw, cmd)
+	set := setFlags(fs)
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			// the top-level values are applied to the global flags
+			// and to the sub-commands, only the ones known to
+			// neither are unknown.
+			if cmd != "" && s.Flags().Lookup(name) == nil {
+				fmt.Fprintf(s.stderr(), "warning: unknown flag %q in %s\n", name, path)
+			}
+			continue
+		}
+		if set[name] || name == configFlagName || name == profileFlagName {
+			continue
+		}
+		for _, v := range values[name] {
+			if err := fs.Set(name, v); err != nil {
+				return fmt.Errorf("command: invalid value %q for flag -%s in %s: %v", v, name, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the flag values of the config file for the named
+// sub-command, the top-level ones overridden by its table. The
+// tables of the other sub-commands are skipped.
+func (s *CommandSet) configValues(raw map[string]interface{}, cmd string) map[string][]string {
+	values := make(map[string][]string, len(raw))
+	for name, v := range raw {
+		if _, ok := v.(map[string]interface{}); ok {
+			if _, isCmd := s.cmds[name]; isCmd || (name == profilesKey && s.profilesEnabled) {
+				continue
+			}
+		}
+		values[name] = configStrings(v)
+	}
+	if table, ok := raw[cmd].(map[string]interface{}); ok {
+		for name, v := range table {
+			values[name] = configStrings(v)
+		}
+	}
+	return values
+}
+
+// Returns the flag values of a config value, one for each element
+// of an array.
+func configStrings(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return []string{fmt.Sprint(v)}
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		values = append(values, fmt.Sprint(item))
+	}
+	return values
+}
+
+// Loads a config file decoded by the decoder of its extension.
+func loadConfigFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	configFormatsMu.Lock()
+	decode, ok := configFormats[strings.ToLower(filepath.Ext(path))]
+	configFormatsMu.Unlock()
+	if !ok {
+		decode = decodeJSON
+	}
+	var raw map[string]interface{}
+	if err := decode(data, &raw); err != nil {
+		return nil, fmt.Errorf("command: cannot parse %s: %v", path, err)
+	}
+	return raw, nil
+}
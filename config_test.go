@@ -0,0 +1,151 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Writes a temporary config file, returns its path.
+func writeConfigForTesting(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+// Tests if unset flags are populated from the config file.
+func TestConfigFlag(t *testing.T) {
+	path := writeConfigForTesting(t, `{"flag1": true, "name": "from-config", "port": 8080, "unknown": 1}`)
+	defer os.Remove(path)
+	resetForTesting("command1", "-config", path, "-name=from-flag")
+
+	cmd := &configCmd{}
+	EnableConfigFlag()
+	On("command1", "", cmd, []string{"flag1"})
+	out := captureStderr(Parse)
+	if !*cmd.flag1 {
+		t.Error("flag1 should be set from the config file")
+	}
+	if *cmd.name != "from-flag" {
+		t.Errorf("command line value was expected to override the config file, found %v", *cmd.name)
+	}
+	if *cmd.port != 8080 {
+		t.Errorf("expected port 8080, found %v", *cmd.port)
+	}
+	if !strings.Contains(out, `unknown flag "unknown"`) {
+		t.Errorf("a warning for the unknown key was expected, found %q", out)
+	}
+}
+
+// Tests if global flags are populated from the top-level values of
+// the config file.
+func TestConfigGlobalFlags(t *testing.T) {
+	path := writeConfigForTesting(t, `{"verbose": true, "command1": {"port": 80}}`)
+	defer os.Remove(path)
+	resetForTesting("-config", path, "command1")
+
+	verbose := flag.Bool("verbose", false, "")
+	cmd := &configCmd{}
+	EnableConfigFlag()
+	On("command1", "", cmd, nil)
+	out := captureStderr(Parse)
+	if !*verbose || *cmd.port != 80 {
+		t.Errorf("unexpected values %v, %v", *verbose, *cmd.port)
+	}
+	if out != "" {
+		t.Errorf("no warnings were expected, found %q", out)
+	}
+}
+
+// configCmd is a test sub command with flags of various types.
+type configCmd struct {
+	testCmd1
+
+	name *string
+	port *int
+}
+
+func (cmd *configCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	cmd.name = fs.String("name", "", "")
+	cmd.port = fs.Int("port", 0, "")
+	return cmd.testCmd1.Flags(fs)
+}
+
+// Tests if sub-command tables override the top-level values, and the
+// environment overrides the config file.
+func TestConfigPrecedence(t *testing.T) {
+	path := writeConfigForTesting(t, `{"name": "top", "port": 1, "command1": {"port": 1234567}, "command2": {"port": 2}}`)
+	defer os.Remove(path)
+	os.Setenv("TOOL_COMMAND1_NAME", "env")
+	defer os.Unsetenv("TOOL_COMMAND1_NAME")
+	resetForTesting("-config", path, "command1", "-flag1")
+
+	cmd := &configCmd{}
+	EnableConfigFlag()
+	SetEnvPrefix("TOOL")
+	On("command1", "", cmd, nil)
+	On("command2", "", &configCmd{}, nil)
+	out := captureStderr(Parse)
+	if *cmd.name != "env" || *cmd.port != 1234567 {
+		t.Errorf("unexpected values %v, %v", *cmd.name, *cmd.port)
+	}
+	if out != "" {
+		t.Errorf("no warnings were expected, found %q", out)
+	}
+	if FlagSource("port") != "config" {
+		t.Errorf("expected -port from the config, found %q", FlagSource("port"))
+	}
+}
+
+// Tests if the config file is found in the user config directory.
+func TestUserConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(orig func() (string, error)) { userConfigDir = orig }(userConfigDir)
+	userConfigDir = func() (string, error) { return dir, nil }
+	path := filepath.Join(dir, "cmd", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(`{"port": 8080}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	resetForTesting("command1")
+
+	cmd := &configCmd{}
+	EnableConfigFlag()
+	On("command1", "", cmd, nil)
+	Parse()
+	if *cmd.port != 8080 {
+		t.Errorf("expected port 8080, found %v", *cmd.port)
+	}
+	if ConfigFile() != path {
+		t.Errorf("expected %v to be loaded, found %q", path, ConfigFile())
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Name of the sub-command managing the config file.
+const configCmdName = "config"
+
+// EnableConfigCommand enables the config file as EnableConfigFlag
+// does and registers a "config" sub-command reading and writing it:
+//
+//	tool config list
+//	tool config get serve.port
+//	tool config set serve.port 8080
+//	tool config unset serve.port
+//	tool config dump serve -port 9090
+//
+// A key is a flag name, applying to every sub-command defining it, or
+// a sub-command name and one of its flags separated by a dot. Keys
+// and values are validated against the flags. The file given by the
+// global -config flag or found in the user config directory is used,
+// a new one is created in the user config directory. Only JSON files
+// can be written.
+//
+// dump resolves the flags of a sub-command from the flags following
+// its name, the environment, the config file and the value providers
+// as Parse does, and prints each of them with its value and source.
+// The values of flags implementing Secret are redacted.
+func EnableConfigCommand() {
+	defaultSet.EnableConfigCommand()
+}
+
+// EnableConfigCommand registers a "config" sub-command managing the
+// config file of the set.
+func (s *CommandSet) EnableConfigCommand() {
+	s.EnableConfigFlag()
+	set := New(configCmdName)
+	set.On("list", "lists the keys and values", &configFileCmd{set: s, run: (*configFile).listKey}, nil, ExactArgs(0))
+	set.On("get", "prints the value of a key", &configFileCmd{set: s, run: (*configFile).getKey}, nil, Args("key"))
+	set.On("set", "sets the value of a key", &configFileCmd{set: s, run: (*configFile).setKey}, nil, Args("key", "value"))
+	set.On("unset", "removes a key", &configFileCmd{set: s, run: (*configFile).unsetKey}, nil, Args("key"))
+	set.On("dump", "prints the effective flag values of a command", &configDumpCmd{set: s}, nil, DisableFlagParsing())
+	s.OnSet(configCmdName, "reads and writes the config file", set)
+}
+
+// configFileCmd is a sub-command of the config sub-command.
+type configFileCmd struct {
+	set *CommandSet
+	run func(c *configFile, args []string) error
+}
+
+func (cmd *configFileCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	return fs
+}
+
+func (cmd *configFileCmd) Run(args []string) {
+	if err := cmd.RunE(args); err != nil {
+		fmt.Fprintln(cmd.set.stderr(), err)
+	}
+}
+
+func (cmd *configFileCmd) RunE(args []string) error {
+	c, err := cmd.set.openConfigFile()
+	if err != nil {
+		return err
+	}
+	return cmd.run(c, args)
+}
+
+// configFile is a config file being read or written.
+type configFile struct {
+	set    *CommandSet
+	path   string
+	values map[string]interface{}
+}
+
+// Opens the config file of the set, or an empty one at the path a
+// new one is created at.
+func (s *CommandSet) openConfigFile() (*configFile, error) {
+	path := s.configPath(s.Flags())
+	if path == "" {
+		dir, err := userConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, filepath.Base(s.program()), configCmdName+".json")
+	}
+	c := &configFile{set: s, path: path, values: make(map[string]interface{})}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return c, nil
+	}
+	values, err := loadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if values != nil {
+		c.values = values
+	}
+	return c, nil
+}
+
+// Returns the flags a key can be set to, an error if the key or the
+// sub-command it names is unknown.
+func (c *configFile) flags(key string) ([]*flag.Flag, error) {
+	s := c.set
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cmd, name := "", key
+	if i := strings.Index(key, "."); i >= 0 {
+		cmd, name = key[:i], key[i+1:]
+		if cont, ok := s.cmds[cmd]; !ok || cont.name != cmd {
+			return nil, fmt.Errorf("unknown command %q in key %q", cmd, key)
+		}
+	}
+	var flags []*flag.Flag
+	for _, cont := range s.cmds {
+		if (cmd != "" && cont.name != cmd) || cont.disableFlagParsing {
+			continue
+		}
+		if f := s.flagSet(cont, flag.ContinueOnError).Lookup(name); f != nil && name != configFlagName && name != profileFlagName {
+			flags = append(flags, f)
+		}
+	}
+	if len(flags) == 0 {
+		return nil, fmt.Errorf("unknown key %q", key)
+	}
+	return flags, nil
+}
+
+func (c *configFile) listKey(args []string) error {
+	var lines []string
+	for key, v := range c.values {
+		if table, ok := v.(map[string]interface{}); ok {
+			for name, v := range table {
+				lines = append(lines, fmt.Sprintf("%s.%s=%s", key, name, strings.Join(configStrings(v), ",")))
+			}
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s=%s", key, strings.Join(configStrings(v), ",")))
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(c.set.stdout(), line)
+	}
+	return nil
+}
+
+func (c *configFile) getKey(args []string) error {
+	if _, err := c.flags(args[0]); err != nil {
+		return err
+	}
+	table, name := c.table(args[0], false)
+	v, ok := table[name]
+	if !ok {
+		return fmt.Errorf("key %q is not set in %s", args[0], c.path)
+	}
+	fmt.Fprintln(c.set.stdout(), strings.Join(configStrings(v), ","))
+	return nil
+}
+
+func (c *configFile) setKey(args []string) error {
+	flags, err := c.flags(args[0])
+	if err != nil {
+		return err
+	}
+	for _, f := range flags {
+		if err := f.Value.Set(args[1]); err != nil {
+			return fmt.Errorf("invalid value %q for key %q: %v", args[1], args[0], err)
+		}
+	}
+	table, name := c.table(args[0], true)
+	table[name] = args[1]
+	return c.write()
+}
+
+func (c *configFile) unsetKey(args []string) error {
+	if _, err := c.flags(args[0]); err != nil {
+		return err
+	}
+	table, name := c.table(args[0], false)
+	if _, ok := table[name]; !ok {
+		return nil
+	}
+	delete(table, name)
+	if i := strings.Index(args[0], "."); i >= 0 && len(table) == 0 {
+		delete(c.values, args[0][:i])
+	}
+	return c.write()
+}
+
+// Returns the table holding the key and its name in the table,
+// creating a sub-command table if create is set.
+func (c *configFile) table(key string, create bool) (map[string]interface{}, string) {
+	i := strings.Index(key, ".")
+	if i < 0 {
+		return c.values, key
+	}
+	table, ok := c.values[key[:i]].(map[string]interface{})
+	if !ok {
+		table = make(map[string]interface{})
+		if create {
+			c.values[key[:i]] = table
+		}
+	}
+	return table, key[i+1:]
+}
+
+// Writes the config file as JSON.
+func (c *configFile) write() error {
+	if ext := strings.ToLower(filepath.Ext(c.path)); ext != ".json" && ext != "" {
+		return errors.New("command: only JSON config files can be written, not " + c.path)
+	}
+	data, err := json.MarshalIndent(c.values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,127 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests if the config sub-command reads and writes the config file.
+func TestConfigCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(orig func() (string, error)) { userConfigDir = orig }(userConfigDir)
+	userConfigDir = func() (string, error) { return dir, nil }
+
+	run := func(args ...string) (string, error) {
+		s := NewWithErrorHandling("tool", flag.ContinueOnError)
+		var out bytes.Buffer
+		s.SetIO(nil, &out, ioutil.Discard)
+		s.On("serve", "", &configCmd{}, nil)
+		s.EnableConfigCommand()
+		err := s.ParseAndRunE(args)
+		return out.String(), err
+	}
+	for _, args := range [][]string{{"set", "serve.port", "8080"}, {"set", "name", "x"}, {"set", "flag1", "true"}, {"unset", "flag1"}} {
+		if _, err := run(append([]string{"config"}, args...)...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+	if out, err := run("config", "list"); err != nil || out != "name=x\nserve.port=8080\n" {
+		t.Errorf("unexpected list %q, %v", out, err)
+	}
+	if out, err := run("config", "get", "serve.port"); err != nil || out != "8080\n" {
+		t.Errorf("unexpected value %q, %v", out, err)
+	}
+	for args, want := range map[string]string{
+		"set port http":   `invalid value "http"`,
+		"set unknown 1":   `unknown key "unknown"`,
+		"get other.port":  `unknown command "other"`,
+		"get serve.flag1": `not set`,
+	} {
+		if _, err := run(append([]string{"config"}, strings.Fields(args)...)...); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, found %v", args, want, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tool", "config.json")); err != nil {
+		t.Error(err)
+	}
+}
+
+// Tests if config dump prints the values of the flags with their
+// sources, redacting the secrets.
+func TestConfigDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"serve": {"port": 8080}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TOOL_SERVE_NAME", "from-env")
+	defer os.Unsetenv("TOOL_SERVE_NAME")
+
+	s := NewWithErrorHandling("tool", flag.ContinueOnError)
+	var out bytes.Buffer
+	s.SetIO(nil, &out, ioutil.Discard)
+	s.On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.String("name", "", "")
+		fs.Int("port", 0, "")
+		fs.Bool("flag1", false, "")
+		fs.Var(&secretValue{}, "token", "")
+	}}, []string{"flag1"})
+	s.SetEnvPrefix("TOOL")
+	s.EnableConfigCommand()
+	if err := s.ParseAndRunE([]string{"-config", path, "config", "dump", "serve", "-token", "s3cr3t"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"-name    from-env", "-port    8080        config", "-flag1   false       default", "-token   <redacted>  flag"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the dump, found:\n%s", want, out.String())
+		}
+	}
+	if err := s.ParseAndRunE([]string{"config", "dump", "other"}); err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+}
+
+// secretValue is a test flag value holding a secret.
+type secretValue struct {
+	value string
+}
+
+func (v *secretValue) String() string {
+	return v.value
+}
+
+func (v *secretValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func (v *secretValue) IsSecret() bool {
+	return true
+}
@@ -0,0 +1,39 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configformats registers the YAML and TOML config file
+// formats of the -config flag enabled by command.EnableConfigFlag.
+// Import it for its side effect:
+//
+//	import _ "github.com/rakyll/command/configformats"
+package configformats
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/rakyll/command"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	command.RegisterConfigFormat(".yaml", yaml.Unmarshal)
+	command.RegisterConfigFormat(".yml", yaml.Unmarshal)
+	command.RegisterConfigFormat(".toml", decodeTOML)
+}
+
+// Decodes TOML, whose arrays are decoded as []interface{} like
+// the ones of JSON and YAML.
+func decodeTOML(data []byte, v interface{}) error {
+	_, err := toml.Decode(string(data), v)
+	return err
+}
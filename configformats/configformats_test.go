@@ -0,0 +1,71 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configformats
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rakyll/command"
+)
+
+// serveCmd is a test sub command.
+type serveCmd struct {
+	port *int
+	tags []string
+}
+
+func (cmd *serveCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	cmd.port = fs.Int("port", 0, "")
+	fs.Func("tag", "", func(s string) error {
+		cmd.tags = append(cmd.tags, s)
+		return nil
+	})
+	return fs
+}
+
+func (cmd *serveCmd) Run(args []string) {}
+
+// Tests if YAML and TOML config files are loaded.
+func TestFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configformats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"config.yaml": "serve:\n  port: 8080\n  tag: [a, b]\n",
+		"config.toml": "[serve]\nport = 8080\ntag = [\"a\", \"b\"]\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		s := command.New("tool")
+		s.EnableConfigFlag()
+		cmd := &serveCmd{}
+		s.On("serve", "", cmd, nil)
+		if err := s.ParseErr([]string{"-config", path, "serve"}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if *cmd.port != 8080 || len(cmd.tags) != 2 {
+			t.Errorf("%s: unexpected values %v, %v", name, *cmd.port, cmd.tags)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Input stream the prompts read from.
+var stdin io.Reader = os.Stdin
+
+// Reports whether r is an interactive terminal.
+var isTerminal = func(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// YesFlag defines the conventional -yes flag to skip confirmations.
+func YesFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("yes", false, "skips the confirmation prompt")
+}
+
+// Confirm asks the user to confirm the prompt with a "y" or "yes".
+// It returns true without asking if autoYes is set, typically by the
+// -yes flag. If the input is not a terminal, it returns false instead
+// of blocking on a reply that will never come.
+func Confirm(prompt string, autoYes bool) (bool, error) {
+	return defaultSet.Confirm(prompt, autoYes)
+}
+
+// Confirm asks the user to confirm the prompt on the streams of
+// the set.
+func (s *CommandSet) Confirm(prompt string, autoYes bool) (bool, error) {
+	if autoYes {
+		return true, nil
+	}
+	return confirm(s.stdin(), s.stderr(), prompt)
+}
+
+// Asks to confirm the prompt on out, reads the reply from in.
+func confirm(in io.Reader, out io.Writer, prompt string) (bool, error) {
+	if !isTerminal(in) {
+		return false, nil
+	}
+	fmt.Fprintf(out, "%s [y/N] ", prompt)
+	reply, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(reply)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// ErrNotConfirmed is returned by RunErr if the run of a sub-command
+// requiring a confirmation is not confirmed.
+var ErrNotConfirmed = errors.New("command: not confirmed")
+
+// RequireConfirmation asks the user to confirm the prompt before
+// running the sub-command, e.g. "this will delete the cluster,
+// continue?". It defines -yes and its alias -force to skip the
+// confirmation, unless the sub-command defines them. If the input is
+// not a terminal, the run fails unless -yes is set.
+func RequireConfirmation(prompt string) Option {
+	return func(cont *cmdCont) {
+		cont.confirm = prompt
+	}
+}
+
+// Defines the flags skipping the confirmation of the sub-command.
+func defineConfirmFlags(fs *flag.FlagSet, cont *cmdCont) {
+	if cont.confirm == "" || fs.Lookup("yes") != nil {
+		return
+	}
+	YesFlag(fs)
+	if fs.Lookup("force") == nil {
+		Alias(fs, "force", "yes")
+	}
+}
+
+// Returns an error unless the run of the sub-command is confirmed, or
+// doesn't need to be.
+func (res *parseResult) confirmRun() error {
+	if res.cont.confirm == "" || res.flags == nil {
+		return nil
+	}
+	if f := res.flags.Lookup("yes"); f != nil && f.Value.String() == "true" {
+		return nil
+	}
+	in, _, errOut := res.set.streams(res.cont)
+	if !isTerminal(in) {
+		return fmt.Errorf("%s requires a confirmation, pass -yes to run it non-interactively", res.cont.name)
+	}
+	ok, err := confirm(in, errOut, res.cont.confirm)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotConfirmed
+	}
+	return nil
+}
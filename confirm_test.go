@@ -0,0 +1,110 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// Replaces the prompt input for testing.
+func setStdinForTesting(r io.Reader, terminal bool) func() {
+	origStdin, origIsTerminal := stdin, isTerminal
+	stdin = r
+	isTerminal = func(io.Reader) bool { return terminal }
+	return func() { stdin, isTerminal = origStdin, origIsTerminal }
+}
+
+// Tests if the replies to the prompt are interpreted.
+func TestConfirm(t *testing.T) {
+	for reply, expected := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		restore := setStdinForTesting(strings.NewReader(reply), true)
+		var ok bool
+		var err error
+		captureStderr(func() { ok, err = Confirm("continue?", false) })
+		restore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Errorf("reply %q: expected %v, found %v", reply, expected, ok)
+		}
+	}
+}
+
+// Tests if the prompt is skipped when autoYes is set.
+func TestConfirmAutoYes(t *testing.T) {
+	defer setStdinForTesting(strings.NewReader("n\n"), true)()
+	if ok, _ := Confirm("continue?", true); !ok {
+		t.Error("confirmation was expected to be skipped")
+	}
+}
+
+// Tests if non-interactive input is not confirmed.
+func TestConfirmNotTerminal(t *testing.T) {
+	defer setStdinForTesting(strings.NewReader("y\n"), false)()
+	if ok, _ := Confirm("continue?", false); ok {
+		t.Error("confirmation was not expected on non-terminal input")
+	}
+}
+
+// Tests if the prompt is asked on the streams of the set.
+func TestConfirmSetIO(t *testing.T) {
+	defer setStdinForTesting(strings.NewReader("n\n"), true)()
+	var out bytes.Buffer
+	set := New("tool")
+	set.SetIO(strings.NewReader("y\n"), nil, &out)
+	if ok, err := set.Confirm("continue?", false); err != nil || !ok {
+		t.Errorf("expected a confirmation from the input of the set, found %v, %v", ok, err)
+	}
+	if out.String() != "continue? [y/N] " {
+		t.Errorf("unexpected prompt %q", out.String())
+	}
+}
+
+// Tests if sub-commands requiring a confirmation only run once it's
+// given.
+func TestRequireConfirmation(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		reply    string
+		terminal bool
+		run      bool
+	}{
+		{[]string{"delete"}, "y\n", true, true},
+		{[]string{"delete"}, "n\n", true, false},
+		{[]string{"delete"}, "y\n", false, false},
+		{[]string{"delete", "-yes"}, "", false, true},
+		{[]string{"delete", "-force"}, "", false, true},
+	} {
+		restore := setStdinForTesting(nil, tt.terminal)
+		cmd := &testCmd1{}
+		s := NewWithErrorHandling("tool", flag.ContinueOnError)
+		s.SetIO(strings.NewReader(tt.reply), ioutil.Discard, ioutil.Discard)
+		s.On("delete", "", cmd, nil, RequireConfirmation("delete everything?"))
+		err := s.ParseAndRunE(tt.args)
+		restore()
+		if cmd.run != tt.run {
+			t.Errorf("%v with %q: expected run to be %v, found %v", tt.args, tt.reply, tt.run, cmd.run)
+		}
+		if !tt.run && err == nil {
+			t.Errorf("%v with %q: expected an error", tt.args, tt.reply)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// flagGroup is a constraint on how many of its flags are set.
+type flagGroup struct {
+	names []string
+	// whether at most one of the flags can be set.
+	exactly bool
+}
+
+// flagRelation is a constraint on the flags set along with a flag.
+type flagRelation struct {
+	name   string
+	others []string
+	// whether the others can't be set along with the flag,
+	// or are required.
+	conflicts bool
+}
+
+// RequireOneOf requires at least one of the named flags to be set,
+// e.g. "token" or "password-file".
+func RequireOneOf(names ...string) Option {
+	return func(cont *cmdCont) {
+		cont.flagGroups = append(cont.flagGroups, flagGroup{names: names})
+	}
+}
+
+// RequireExactlyOneOf requires exactly one of the named flags to
+// be set.
+func RequireExactlyOneOf(names ...string) Option {
+	return func(cont *cmdCont) {
+		cont.flagGroups = append(cont.flagGroups, flagGroup{names: names, exactly: true})
+	}
+}
+
+// FlagRequires requires the other flags to be set if the named flag
+// is set, e.g. "tls-key" if "tls-cert" is.
+func FlagRequires(name string, others ...string) Option {
+	return func(cont *cmdCont) {
+		cont.flagRelations = append(cont.flagRelations, flagRelation{name: name, others: others})
+	}
+}
+
+// FlagConflicts forbids the other flags to be set if the named flag
+// is set, e.g. "dry-run" if "force" is.
+func FlagConflicts(name string, others ...string) Option {
+	return func(cont *cmdCont) {
+		cont.flagRelations = append(cont.flagRelations, flagRelation{name: name, others: others, conflicts: true})
+	}
+}
+
+// Returns the violations of the flag constraints of the sub-command.
+func (cont *cmdCont) checkConstraints(fs *flag.FlagSet) []error {
+	set := setFlags(fs)
+	var errs []error
+	for _, g := range cont.flagGroups {
+		n := 0
+		for _, name := range g.names {
+			if set[name] {
+				n++
+			}
+		}
+		switch {
+		case n == 0:
+			errs = append(errs, fmt.Errorf("one of %s is required", dashed(g.names)))
+		case n > 1 && g.exactly:
+			errs = append(errs, fmt.Errorf("only one of %s can be set", dashed(g.names)))
+		}
+	}
+	for _, r := range cont.flagRelations {
+		if !set[r.name] {
+			continue
+		}
+		for _, other := range r.others {
+			switch {
+			case r.conflicts && set[other]:
+				errs = append(errs, fmt.Errorf("-%s conflicts with -%s", r.name, other))
+			case !r.conflicts && !set[other]:
+				errs = append(errs, fmt.Errorf("-%s requires -%s", r.name, other))
+			}
+		}
+	}
+	return errs
+}
+
+// Prints the flag constraints of the sub-command, if it has any.
+func (cont *cmdCont) printConstraints(w io.Writer) {
+	if len(cont.flagGroups) == 0 && len(cont.flagRelations) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", UsageStrings.Constraints)
+	for _, g := range cont.flagGroups {
+		if g.exactly {
+			fmt.Fprintf(w, "  exactly one of %s\n", dashed(g.names))
+		} else {
+			fmt.Fprintf(w, "  at least one of %s\n", dashed(g.names))
+		}
+	}
+	for _, r := range cont.flagRelations {
+		if r.conflicts {
+			fmt.Fprintf(w, "  -%s conflicts with %s\n", r.name, dashed(r.others))
+		} else {
+			fmt.Fprintf(w, "  -%s requires %s\n", r.name, dashed(r.others))
+		}
+	}
+}
+
+// Returns the flag names with a dash, separated by commas.
+func dashed(names []string) string {
+	return "-" + strings.Join(names, ", -")
+}
@@ -0,0 +1,96 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+// authCmd is a test sub command with alternative credential flags.
+type authCmd struct {
+	testCmd1
+}
+
+func (cmd *authCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	fs.String("token", "", "")
+	fs.String("password-file", "", "")
+	return fs
+}
+
+// Tests if one-of flag groups are validated.
+func TestRequireOneOf(t *testing.T) {
+	for _, tt := range []struct {
+		args    []string
+		opt     Option
+		wantErr string
+	}{
+		{[]string{"login"}, RequireOneOf("token", "password-file"), "one of -token, -password-file is required"},
+		{[]string{"login", "-token=x"}, RequireOneOf("token", "password-file"), ""},
+		{[]string{"login", "-token=x", "-password-file=y"}, RequireOneOf("token", "password-file"), ""},
+		{[]string{"login", "-token=x", "-password-file=y"}, RequireExactlyOneOf("token", "password-file"), "only one of -token, -password-file can be set"},
+	} {
+		resetForTesting(tt.args...)
+		On("login", "", &authCmd{}, nil, tt.opt)
+		err := ParseErr()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%v: unexpected error %v", tt.args, err)
+		}
+		if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("%v: expected %q, found %v", tt.args, tt.wantErr, err)
+		}
+	}
+	if usage, _ := SubcommandUsageString("login"); !strings.Contains(usage, "exactly one of -token, -password-file") {
+		t.Errorf("expected the constraint in the usage, found %q", usage)
+	}
+}
+
+// Tests if flag dependencies and conflicts are validated.
+func TestFlagRelations(t *testing.T) {
+	for _, tt := range []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"login", "-token=x"}, "-token requires -password-file"},
+		{[]string{"login", "-token=x", "-password-file=y"}, ""},
+		{[]string{"login", "-flag1", "-token=x", "-password-file=y"}, "-flag1 conflicts with -token"},
+		{[]string{"login"}, ""},
+	} {
+		resetForTesting(tt.args...)
+		On("login", "", &flagsCmd{}, nil, FlagRequires("token", "password-file"), FlagConflicts("flag1", "token"))
+		err := ParseErr()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%v: unexpected error %v", tt.args, err)
+		}
+		if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("%v: expected %q, found %v", tt.args, tt.wantErr, err)
+		}
+	}
+	usage, _ := SubcommandUsageString("login")
+	if !strings.Contains(usage, "-token requires -password-file") || !strings.Contains(usage, "-flag1 conflicts with -token") {
+		t.Errorf("expected the constraints in the usage, found %q", usage)
+	}
+}
+
+// flagsCmd is a test sub command with flag1 and credential flags.
+type flagsCmd struct {
+	authCmd
+}
+
+func (cmd *flagsCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	fs.Bool("flag1", false, "")
+	return cmd.authCmd.Flags(fs)
+}
@@ -0,0 +1,91 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strings"
+)
+
+// SetDefault sets the sub-command the arguments are parsed by if
+// they don't start with a sub-command name after the global flags,
+// e.g. `program -v` is parsed as `program serve -v` if the default
+// is "serve". The default is marked in the usage.
+func SetDefault(name string) {
+	defaultSet.SetDefault(name)
+}
+
+// SetDefault sets the default sub-command of the set.
+func (s *CommandSet) SetDefault(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultCmd = name
+}
+
+// Returns the arguments with the default sub-command name inserted
+// after the global flags, if it's set and the arguments don't
+// provide a registered sub-command name there.
+func (s *CommandSet) withDefaultCmd(arguments []string) []string {
+	if _, ok := s.cmds[s.defaultCmd]; !ok {
+		return arguments
+	}
+	globals := s.Flags()
+	i := 0
+	for i < len(arguments) {
+		arg := arguments[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.Index(name, "=")]
+		}
+		f := globals.Lookup(name)
+		if f == nil && name != "h" && name != "help" {
+			// a flag of the default sub-command.
+			break
+		}
+		i++
+		if f != nil && !hasValue && !isBoolFlag(f) {
+			// skip the value.
+			i++
+		}
+	}
+	if i < len(arguments) {
+		if _, ok := s.cmds[arguments[i]]; ok {
+			return arguments
+		}
+	}
+	if i > len(arguments) {
+		return arguments
+	}
+	args := append([]string{}, arguments[:i]...)
+	args = append(args, s.defaultCmd)
+	return append(args, arguments[i:]...)
+}
+
+// Reports whether the flag can be provided without a value.
+func isBoolFlag(f *flag.Flag) bool {
+	return isBoolValue(f.Value)
+}
+
+// Reports whether the value of a flag can be provided without one.
+func isBoolValue(v flag.Value) bool {
+	b, ok := v.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
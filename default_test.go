@@ -0,0 +1,61 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests if the arguments are parsed by the default sub-command if
+// they don't start with a sub-command name.
+func TestSetDefault(t *testing.T) {
+	resetForTesting("-global1=hello", "-flag1", "somearg")
+
+	flagGlobal1 := flag.String("global1", "", "")
+	c1 := &testCmd1{}
+	On("command1", "runs command1", c1, []string{})
+	On("command2", "", &testCmd2{}, []string{})
+	SetDefault("command1")
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunErr(); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run || !*c1.flag1 || *flagGlobal1 != "hello" {
+		t.Error("command 'command1' was expected to run with -global1 and -flag1")
+	}
+	if args := parsedArgs(); !reflect.DeepEqual(args, []string{"somearg"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+	if !strings.Contains(UsageString(), "runs command1 (default)") {
+		t.Error("the default was expected to be marked in the usage")
+	}
+
+	resetForTesting("command2", "-flag2")
+	c2 := &testCmd2{}
+	On("command1", "", &testCmd1{}, []string{})
+	On("command2", "", c2, []string{})
+	SetDefault("command1")
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !*c2.flag2 {
+		t.Error("command 'command2' was expected to run with -flag2")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+)
+
+// DeprecateFlag renames the flag old of the named sub-command to new.
+// The old name is still parsed, its value is set to the new flag and
+// a warning is printed once. It is not listed in the sub-command usage.
+func DeprecateFlag(cmd, old, new string) {
+	defaultSet.DeprecateFlag(cmd, old, new)
+}
+
+// DeprecateFlag renames the flag old of the named sub-command
+// of the set to new.
+func (s *CommandSet) DeprecateFlag(cmd, old, new string) {
+	if s.deprecatedFlags[cmd] == nil {
+		s.deprecatedFlags[cmd] = make(map[string]string)
+	}
+	s.deprecatedFlags[cmd][old] = new
+}
+
+// Deprecated marks the sub-command deprecated with the message, which
+// is printed in the usage and as a warning once it's run. If the
+// replacement is the name of a registered sub-command, the arguments
+// are parsed and run by it instead.
+func Deprecated(message, replacement string) Option {
+	return func(cont *cmdCont) {
+		cont.deprecated = message
+		cont.replacement = replacement
+	}
+}
+
+// Prints a warning once if the sub-command is deprecated, and returns
+// the sub-command to run in its place.
+func (s *CommandSet) warnDeprecated(cont *cmdCont) *cmdCont {
+	if cont.deprecated == "" {
+		return cont
+	}
+	if !cont.warned {
+		cont.warned = true
+		fmt.Fprintf(s.stderr(), "warning: command %q is deprecated: %s\n", cont.name, cont.deprecated)
+	}
+	if replacement, ok := s.cmds[cont.replacement]; ok {
+		return replacement
+	}
+	return cont
+}
+
+// Defines the deprecated flags of the sub-command on fs.
+func (s *CommandSet) defineDeprecatedFlags(fs *flag.FlagSet, cont *cmdCont) {
+	for old, new := range s.deprecatedFlags[cont.name] {
+		if target := fs.Lookup(new); target != nil {
+			fs.Var(&deprecatedValue{fs: fs, cmd: cont.name, old: old, target: target, warned: s.warnedFlags}, old, "")
+		}
+	}
+}
+
+// deprecatedValue forwards the value of a deprecated flag
+// to its replacement.
+type deprecatedValue struct {
+	fs     *flag.FlagSet
+	cmd    string
+	old    string
+	target *flag.Flag
+	// deprecated flags a warning has been printed for.
+	warned map[string]bool
+}
+
+func (v *deprecatedValue) String() string {
+	if v.target == nil {
+		return ""
+	}
+	return v.target.Value.String()
+}
+
+func (v *deprecatedValue) Set(value string) error {
+	if key := v.cmd + " -" + v.old; !v.warned[key] {
+		v.warned[key] = true
+		fmt.Fprintf(v.fs.Output(), "warning: flag -%s is deprecated, use -%s instead\n", v.old, v.target.Name)
+	}
+	return v.fs.Set(v.target.Name, value)
+}
+
+func (v *deprecatedValue) IsBoolFlag() bool {
+	return isBoolFlag(v.target)
+}
+
+// DeprecatedFlag marks the flag name of fs deprecated with the
+// message, e.g. in the Flags of a sub-command. The flag is still
+// parsed, a warning is printed once it's provided and the message is
+// appended to its usage. It panics if name is not defined.
+func DeprecatedFlag(fs *flag.FlagSet, name, message string) {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("command: deprecation of undefined flag -%s", name))
+	}
+	f.Value = &deprecatedFlagValue{valueWrapper: valueWrapper{f.Value}, fs: fs, name: name, message: message}
+	f.Usage = fmt.Sprintf("%s (deprecated: %s)", f.Usage, message)
+}
+
+// deprecatedFlagValue warns once the deprecated flag it wraps is set.
+type deprecatedFlagValue struct {
+	valueWrapper
+	fs      *flag.FlagSet
+	name    string
+	message string
+	warned  bool
+}
+
+func (v *deprecatedFlagValue) Set(value string) error {
+	if !v.warned {
+		v.warned = true
+		fmt.Fprintf(v.fs.Output(), "warning: flag -%s is deprecated: %s\n", v.name, v.message)
+	}
+	return v.Value.Set(value)
+}
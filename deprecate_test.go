@@ -0,0 +1,88 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+// Tests if a deprecated flag sets its replacement.
+func TestDeprecateFlag(t *testing.T) {
+	resetForTesting("command1", "-old-flag1")
+
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{"flag1"})
+	DeprecateFlag("command1", "old-flag1", "flag1")
+	out := captureStderr(Parse)
+	if !*c1.flag1 {
+		t.Errorf("flag1 should be set: expected true, found %v", *c1.flag1)
+	}
+	if !strings.Contains(out, "-old-flag1 is deprecated, use -flag1") {
+		t.Errorf("a deprecation warning was expected, found %q", out)
+	}
+	usage, _ := SubcommandUsageString("command1")
+	if strings.Contains(usage, "old-flag1") {
+		t.Errorf("deprecated flag was not expected in the usage, found %q", usage)
+	}
+}
+
+// Tests if deprecated commands warn once and forward to their
+// replacement.
+func TestDeprecatedCommand(t *testing.T) {
+	resetForTesting("old", "-flag1")
+
+	c1 := &testCmd1{}
+	On("new", "", c1, nil)
+	On("old", "runs old", &testCmd1{}, nil, Deprecated("use new instead", "new"))
+	out := captureStderr(func() {
+		if err := ParseAndRunE(); err != nil {
+			t.Fatal(err)
+		}
+		ParseAndRunE()
+	})
+	if out != "warning: command \"old\" is deprecated: use new instead\n" {
+		t.Errorf("expected a single warning, found %q", out)
+	}
+	if !c1.run || !*c1.flag1 {
+		t.Error("command 'new' was expected to run in place of 'old'")
+	}
+	if !strings.Contains(UsageString(), "runs old (deprecated: use new instead)") {
+		t.Errorf("expected the deprecation in the usage, found %q", UsageString())
+	}
+}
+
+// Tests if a deprecated flag warns once and is still parsed.
+func TestDeprecatedFlag(t *testing.T) {
+	resetForTesting("command1", "-legacy", "-legacy=false")
+
+	var legacy *bool
+	On("command1", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		legacy = fs.Bool("legacy", true, "uses the legacy mode")
+		DeprecatedFlag(fs, "legacy", "it's the default")
+	}}, nil)
+	out := captureStderr(Parse)
+	if *legacy {
+		t.Error("-legacy was expected to be parsed")
+	}
+	if out != "warning: flag -legacy is deprecated: it's the default\n" {
+		t.Errorf("expected a single warning, found %q", out)
+	}
+	usage, _ := SubcommandUsageString("command1")
+	if !strings.Contains(usage, "uses the legacy mode (deprecated: it's the default)") {
+		t.Errorf("expected the deprecation in the usage, found %q", usage)
+	}
+}
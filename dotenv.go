@@ -0,0 +1,108 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Set by the nodotenv build tag to ignore SetDotEnv, e.g. in
+// production builds.
+var dotEnvDisabled = false
+
+// SetDotEnv loads the dotenv file at path, ".env" if empty, into the
+// environment during Parse, before the flags are resolved from the
+// environment. Variables already in the environment are kept. A
+// missing file is ignored. Building with the nodotenv tag disables
+// the loading.
+//
+//	# comment
+//	export MYTOOL_TOKEN="s3cret"
+//	MYTOOL_PORT=9090
+func SetDotEnv(path string) {
+	defaultSet.SetDotEnv(path)
+}
+
+// SetDotEnv loads the dotenv file at path during Parse of the set.
+func (s *CommandSet) SetDotEnv(path string) {
+	if path == "" {
+		path = ".env"
+	}
+	s.dotEnv = path
+}
+
+// Loads the dotenv file of the set, if any.
+func (s *CommandSet) loadDotEnv() error {
+	if s.dotEnv == "" || dotEnvDisabled {
+		return nil
+	}
+	vars, err := readDotEnv(s.dotEnv)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, kv := range vars {
+		if _, ok := os.LookupEnv(kv[0]); !ok {
+			os.Setenv(kv[0], kv[1])
+		}
+	}
+	return nil
+}
+
+// Reads the variables of a dotenv file in order.
+func readDotEnv(path string) ([][2]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var vars [][2]string
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("command: %s:%d: expected KEY=VALUE", path, n)
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("command: %s:%d: %v", path, n, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			// strip a trailing comment.
+			if j := strings.Index(value, " #"); j >= 0 {
+				value = strings.TrimSpace(value[:j])
+			}
+		}
+		vars = append(vars, [2]string{key, value})
+	}
+	return vars, scanner.Err()
+}
@@ -0,0 +1,84 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests if the variables of a dotenv file are read.
+func TestReadDotEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	content := "# comment\n\nexport A=1\nB = two words # note\nC=\"line\\nbreak\"\nD='single # quoted'\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	vars, err := readDotEnv(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"A", "1"}, {"B", "two words"}, {"C", "line\nbreak"}, {"D", "single # quoted"}}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("expected %q, found %q", want, vars)
+	}
+	if err := ioutil.WriteFile(path, []byte("novalue\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readDotEnv(path); err == nil {
+		t.Error("an error was expected for a line without =")
+	}
+}
+
+// Tests if flags are resolved from a dotenv file.
+func TestDotEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(path, []byte("MYTOOL_SERVE_PORT=9090\nMYTOOL_SERVE_HOST=dotenv\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("MYTOOL_SERVE_HOST", "env")
+	defer os.Unsetenv("MYTOOL_SERVE_HOST")
+	defer os.Unsetenv("MYTOOL_SERVE_PORT")
+	resetForTesting("serve")
+
+	var port *int
+	var host *string
+	On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		port = fs.Int("port", 8080, "")
+		host = fs.String("host", "", "")
+	}}, nil)
+	SetEnvPrefix("MYTOOL")
+	SetDotEnv(path)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9090 || *host != "env" {
+		t.Errorf("unexpected values %v, %v", *port, *host)
+	}
+}
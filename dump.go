@@ -0,0 +1,108 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Secret is implemented by flag values holding secrets, e.g.
+// passwords or tokens. Their values are redacted by config dump.
+type Secret interface {
+	flag.Value
+	IsSecret() bool
+}
+
+// Printed in place of the value of a secret flag.
+const redacted = "<redacted>"
+
+// configDumpCmd prints the effective flag values of a sub-command.
+type configDumpCmd struct {
+	set *CommandSet
+}
+
+func (cmd *configDumpCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	return fs
+}
+
+func (cmd *configDumpCmd) Run(args []string) {
+	if err := cmd.RunE(args); err != nil {
+		fmt.Fprintln(cmd.set.stderr(), err)
+	}
+}
+
+func (cmd *configDumpCmd) RunE(args []string) error {
+	w := tabwriter.NewWriter(cmd.set.stdout(), 0, 4, 2, ' ', 0)
+	if err := cmd.set.dumpFlags(w, args); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Resolves the flags of the sub-command named by the first of args
+// from the rest of them, the environment, the config file and the
+// value providers, as Parse does, and prints each flag with its value
+// and source to w.
+func (s *CommandSet) dumpFlags(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing command name")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cont, ok := s.cmds[args[0]]
+	if !ok || cont.hidden {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	if n, ok := cont.cmd().(*nestedCmd); ok {
+		return n.set.dumpFlags(w, args[1:])
+	}
+	if cont.disableFlagParsing {
+		return fmt.Errorf("command %q doesn't parse flags", cont.name)
+	}
+	// the missing flags are reported, not prompted for.
+	defer func(interactive bool) { s.interactive = interactive }(s.interactive)
+	s.interactive = false
+	res, err := s.parseCmd(cont, args[1:], flag.ContinueOnError)
+	if res.help {
+		return nil
+	}
+	// the values are printed even if they fail the validation, e.g.
+	// to find out why a required flag is missing.
+	if _, ok := err.(Errors); err != nil && !ok {
+		return err
+	}
+	res.flags.VisitAll(func(f *flag.Flag) {
+		if isAuxFlag(f.Value) {
+			return
+		}
+		if f.Name == "h" {
+			return
+		}
+		value := f.Value.String()
+		isSecret := wrapsValue(f.Value, func(v flag.Value) bool {
+			secret, ok := v.(Secret)
+			return ok && secret.IsSecret()
+		})
+		if isSecret {
+			value = redacted
+		}
+		fmt.Fprintf(w, "-%s\t%s\t%s\n", f.Name, value, res.source(f.Name))
+	})
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SetEnvPrefix resolves the flags which are not set on the command
+// line from environment variables named by the prefix, the
+// sub-command name and the flag name in upper case with dashes as
+// underscores, e.g. MYTOOL_SERVE_PORT for -port of "serve" with the
+// prefix "MYTOOL". Global flags omit the sub-command name, e.g.
+// MYTOOL_VERBOSE. The environment takes precedence over config files
+// and defaults. Nested sets use the prefix of their parent followed
+// by their name unless they have one.
+func SetEnvPrefix(prefix string) {
+	defaultSet.SetEnvPrefix(prefix)
+}
+
+// SetEnvPrefix resolves the unset flags of the set and its
+// sub-commands from environment variables named by the prefix.
+func (s *CommandSet) SetEnvPrefix(prefix string) {
+	s.envPrefix = prefix
+}
+
+// EnvName returns the environment variable the flag of the named
+// sub-command is resolved from, or the global flag if cmd is empty.
+// It returns an empty string if no prefix is set.
+func EnvName(cmd, flagName string) string {
+	return defaultSet.EnvName(cmd, flagName)
+}
+
+// EnvName returns the environment variable the flag of the named
+// sub-command of the set is resolved from.
+func (s *CommandSet) EnvName(cmd, flagName string) string {
+	prefix := s.envNamePrefix()
+	if prefix == "" {
+		return ""
+	}
+	if cmd != "" {
+		prefix += "_" + envCase(cmd)
+	}
+	return prefix + "_" + envCase(flagName)
+}
+
+// Returns the prefix of the environment variables of the set, the
+// one of its parent followed by its name if it's not set.
+func (s *CommandSet) envNamePrefix() string {
+	if s.envPrefix != "" || s.parent == nil {
+		return s.envPrefix
+	}
+	if prefix := s.parent.envNamePrefix(); prefix != "" {
+		return prefix + "_" + envCase(s.cmdName)
+	}
+	return ""
+}
+
+// Returns name in upper case with dashes and dots as underscores.
+func envCase(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// Sets the flags of fs which are not set on the command line from
+// their environment variables, cmd is empty for the global flags.
+func (s *CommandSet) applyEnv(fs *flag.FlagSet, cmd string) error {
+	if s.envNamePrefix() == "" {
+		return nil
+	}
+	set := setFlags(fs)
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] || f.Name == "h" {
+			return
+		}
+		if isAuxFlag(f.Value) {
+			return
+		}
+		name := s.EnvName(cmd, f.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return
+		}
+		if e := fs.Set(f.Name, value); e != nil {
+			err = fmt.Errorf("command: invalid value %q for flag -%s in $%s: %v", value, f.Name, name, e)
+		}
+	})
+	return err
+}
+
+// Appends the environment variables of the flags of fs to their
+// usage, e.g. "[env: MYTOOL_PORT]", and returns a function restoring
+// the usage.
+func (s *CommandSet) annotateEnv(fs *flag.FlagSet, cmd string) (restore func()) {
+	usages := make(map[*flag.Flag]string)
+	restore = func() {
+		for f, usage := range usages {
+			f.Usage = usage
+		}
+	}
+	if s.envNamePrefix() == "" {
+		return
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if isAuxFlag(f.Value) {
+			return
+		}
+		if f.Name != "h" {
+			usages[f] = f.Usage
+			f.Usage = strings.TrimSpace(fmt.Sprintf("%s [env: %s]", f.Usage, s.EnvName(cmd, f.Name)))
+		}
+	})
+	return
+}
+
+// Sources of flag values.
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceConfig  = "config"
+	sourcePrompt  = "prompt"
+	sourceDefault = "default"
+)
+
+// Records the source of the flags of fs set since the sources were
+// last marked, returns the sources.
+func markSources(fs *flag.FlagSet, sources map[string]string, source string) map[string]string {
+	if sources == nil {
+		sources = make(map[string]string)
+	}
+	for name := range setFlags(fs) {
+		if _, ok := sources[name]; !ok {
+			sources[name] = source
+		}
+	}
+	return sources
+}
+
+// FlagSource reports where the value of the flag of the sub-command
+// matched by Parse comes from: "flag" for the command line,
+// "profile:<name>", "env", "config", the name of a value provider,
+// "prompt" or "default". It returns an empty string if the flag is
+// not defined.
+func FlagSource(name string) string {
+	return defaultSet.FlagSource(name)
+}
+
+// FlagSource reports where the value of the flag of the sub-command
+// of the set matched by Parse comes from.
+func (s *CommandSet) FlagSource(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matching == nil || s.matching.flags == nil || s.matching.flags.Lookup(name) == nil {
+		return ""
+	}
+	return s.matching.source(name)
+}
+
+// Returns the source of the value of the named flag.
+func (res *parseResult) source(name string) string {
+	if source, ok := res.sources[name]; ok {
+		return source
+	}
+	return sourceDefault
+}
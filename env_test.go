@@ -0,0 +1,114 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Tests if unset flags are resolved from the environment.
+func TestEnvPrefix(t *testing.T) {
+	resetForTesting("-verbose=false", "serve", "-host=example.com")
+	for k, v := range map[string]string{
+		"MYTOOL_VERBOSE":       "true",
+		"MYTOOL_SERVE_PORT":    "9090",
+		"MYTOOL_SERVE_HOST":    "localhost",
+		"MYTOOL_SERVE_DRY_RUN": "true",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	verbose := flag.Bool("verbose", true, "")
+	var port *int
+	var host *string
+	var dryRun *bool
+	On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		port = fs.Int("port", 8080, "")
+		host = fs.String("host", "", "")
+		dryRun = fs.Bool("dry-run", false, "")
+	}}, nil)
+	SetEnvPrefix("MYTOOL")
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if *verbose || *port != 9090 || *host != "example.com" || !*dryRun {
+		t.Errorf("unexpected values %v, %v, %v, %v", *verbose, *port, *host, *dryRun)
+	}
+	if name := EnvName("serve", "dry-run"); name != "MYTOOL_SERVE_DRY_RUN" {
+		t.Errorf("expected MYTOOL_SERVE_DRY_RUN, found %v", name)
+	}
+
+	os.Setenv("MYTOOL_SERVE_PORT", "http")
+	resetForTesting("serve")
+	On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.Int("port", 8080, "")
+	}}, nil)
+	SetEnvPrefix("MYTOOL")
+	if err := ParseErr(); err == nil || !strings.Contains(err.Error(), "$MYTOOL_SERVE_PORT") {
+		t.Errorf("expected an invalid value error, found %v", err)
+	}
+}
+
+// Tests if nested sets extend the prefix of their parent.
+func TestNestedEnvName(t *testing.T) {
+	resetForTesting()
+
+	remote := New("remote")
+	OnSet("remote", "", remote)
+	SetEnvPrefix("GIT")
+	if name := remote.EnvName("add", "fetch"); name != "GIT_REMOTE_ADD_FETCH" {
+		t.Errorf("expected GIT_REMOTE_ADD_FETCH, found %v", name)
+	}
+}
+
+// Tests if the environment variables are listed in the usage and the
+// sources of the values are reported.
+func TestEnvUsageAndSources(t *testing.T) {
+	resetForTesting("serve", "-host=example.com")
+	os.Setenv("MYTOOL_SERVE_PORT", "9090")
+	defer os.Unsetenv("MYTOOL_SERVE_PORT")
+
+	flag.Bool("verbose", false, "prints more")
+	On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.Int("port", 8080, "listen port")
+		fs.String("host", "", "listen host")
+		fs.Bool("tls", false, "")
+	}}, nil)
+	SetEnvPrefix("MYTOOL")
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"host": "flag", "port": "env", "tls": "default", "missing": ""} {
+		if found := FlagSource(name); found != want {
+			t.Errorf("expected the source of -%s to be %q, found %q", name, want, found)
+		}
+	}
+	usage, _ := SubcommandUsageString("serve")
+	for _, s := range []string{"listen port [env: MYTOOL_SERVE_PORT]", "[env: MYTOOL_SERVE_TLS]"} {
+		if !strings.Contains(usage, s) {
+			t.Errorf("expected the usage to contain %q, found %q", s, usage)
+		}
+	}
+	if usage := UsageString(); !strings.Contains(usage, "prints more [env: MYTOOL_VERBOSE]") {
+		t.Errorf("expected the usage to list MYTOOL_VERBOSE, found %q", usage)
+	}
+	if f := flag.Lookup("verbose"); f.Usage != "prints more" {
+		t.Errorf("expected the usage of the global flag to be restored, found %q", f.Usage)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// ValueFromFile lets the flags of fs with the names read their value
+// from a file, as in -token @/run/secrets/token, or from stdin, as
+// in -config -, to keep it out of process listings and the shell
+// history. A single trailing newline is trimmed, a leading "@@"
+// escapes a literal "@". It panics if a name is not defined.
+func ValueFromFile(fs *flag.FlagSet, names ...string) {
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			panic(fmt.Sprintf("command: file value of undefined flag -%s", name))
+		}
+		f.Value = &fileValue{valueWrapper: valueWrapper{f.Value}}
+	}
+}
+
+// fileValue reads the value of the flag it wraps from a file.
+type fileValue struct {
+	valueWrapper
+	// the input "-" is read from, stdin if nil.
+	in io.Reader
+}
+
+func (v *fileValue) SetIO(in io.Reader, out, err io.Writer) {
+	v.in = in
+}
+
+func (v *fileValue) Set(s string) error {
+	var b []byte
+	var err error
+	switch {
+	case s == "-":
+		in := v.in
+		if in == nil {
+			in = stdin
+		}
+		b, err = ioutil.ReadAll(in)
+	case strings.HasPrefix(s, "@@"):
+		return v.Value.Set(s[1:])
+	case strings.HasPrefix(s, "@"):
+		b, err = ioutil.ReadFile(s[1:])
+	default:
+		return v.Value.Set(s)
+	}
+	if err != nil {
+		return err
+	}
+	s = strings.TrimSuffix(string(b), "\n")
+	return v.Value.Set(strings.TrimSuffix(s, "\r"))
+}
@@ -0,0 +1,72 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests if flag values are read from files and stdin.
+func TestValueFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(path, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer setStdinForTesting(strings.NewReader("{}\n"), false)()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	token := fs.String("token", "", "")
+	config := fs.String("config", "", "")
+	user := fs.String("user", "", "")
+	ValueFromFile(fs, "token", "config", "user")
+	if err := fs.Parse([]string{"-token", "@" + path, "-config", "-", "-user=@@me"}); err != nil {
+		t.Fatal(err)
+	}
+	if *token != "s3cret" || *config != "{}" || *user != "@me" {
+		t.Errorf("unexpected values: %q, %q, %q", *token, *config, *user)
+	}
+	if err := fs.Set("token", "@"+filepath.Join(dir, "missing")); err == nil {
+		t.Error("an error was expected for a missing file")
+	}
+}
+
+// Tests if "-" is read from the input of the set.
+func TestValueFromFileSetIO(t *testing.T) {
+	defer setStdinForTesting(strings.NewReader("stdin\n"), false)()
+	resetForTesting("command1", "-config", "-")
+
+	var config *string
+	On("command1", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		config = fs.String("config", "", "")
+		ValueFromFile(fs, "config")
+	}}, nil)
+	SetIO(strings.NewReader("{}\n"), nil, nil)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if *config != "{}" {
+		t.Errorf("expected the value from the input of the set, found %q", *config)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"sync"
+)
+
+// Number of groups assigned so far, orders the groups in the usage.
+var (
+	groupsMu sync.Mutex
+	groups   int
+)
+
+// GroupFlags assigns the flags of fs with the names to the named
+// group, e.g. "connection options". The usage lists the flags
+// without a group first, then each group under its name in the
+// order they are assigned. It panics if a name is not defined.
+func GroupFlags(fs *flag.FlagSet, group string, names ...string) {
+	groupsMu.Lock()
+	groups++
+	order := groups
+	groupsMu.Unlock()
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			panic(fmt.Sprintf("command: grouping undefined flag -%s", name))
+		}
+		f.Value = &groupValue{valueWrapper: valueWrapper{f.Value}, group: group, order: order}
+	}
+}
+
+// groupValue is the value of a flag assigned to a group.
+type groupValue struct {
+	valueWrapper
+	group string
+	order int
+}
+
+// Returns the group of the flag, nil if it has none.
+func groupOf(f *flag.Flag) *groupValue {
+	var g *groupValue
+	wrapsValue(f.Value, func(v flag.Value) bool {
+		g, _ = v.(*groupValue)
+		return g != nil
+	})
+	return g
+}
@@ -0,0 +1,415 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OptionalString defines a string flag whose value can be omitted,
+// e.g. -color, -color=always and -color=never. The flag is set to
+// ifPresent if it's provided without a value, and to an empty string
+// if it's not provided. As with bool flags, a value must be attached
+// with "=", and -color=true is treated as -color.
+func OptionalString(fs *flag.FlagSet, name, ifPresent, usage string) *string {
+	v := &optionalString{ifPresent: ifPresent}
+	fs.Var(v, name, fmt.Sprintf("%s (-%s alone means %q)", usage, name, ifPresent))
+	return &v.value
+}
+
+type optionalString struct {
+	value     string
+	ifPresent string
+}
+
+func (v *optionalString) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *optionalString) Set(s string) error {
+	if s == "true" {
+		s = v.ifPresent
+	}
+	v.value = s
+	return nil
+}
+
+// IsBoolFlag allows the flag to be provided without a value.
+func (v *optionalString) IsBoolFlag() bool {
+	return true
+}
+
+// Alias defines alias as another name of the flag name of fs, e.g.
+// "v" for "verbose". Setting the alias sets the flag, as if it was
+// provided. The usage lists them together as "-v, -verbose". It
+// panics if name is not defined.
+func Alias(fs *flag.FlagSet, alias, name string) {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("command: alias of undefined flag -%s", name))
+	}
+	fs.Var(&aliasValue{fs: fs, name: name}, alias, f.Usage)
+}
+
+// aliasValue is the value of a flag alias, it sets the flag it names
+// when set.
+type aliasValue struct {
+	fs   *flag.FlagSet
+	name string
+}
+
+// Returns the flag the alias names, nil if it's not defined.
+func (v *aliasValue) target() *flag.Flag {
+	if v == nil || v.fs == nil {
+		return nil
+	}
+	return v.fs.Lookup(v.name)
+}
+
+func (v *aliasValue) String() string {
+	if f := v.target(); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+func (v *aliasValue) Set(s string) error {
+	return v.fs.Set(v.name, s)
+}
+
+// IsBoolFlag allows the alias of a bool flag to be provided
+// without a value.
+func (v *aliasValue) IsBoolFlag() bool {
+	f := v.target()
+	return f != nil && isBoolFlag(f)
+}
+
+// NegatableBool defines a bool flag which is also set to false by
+// -no-<name>, e.g. -no-color to override a default from a config file.
+// Setting -no-<name> sets the flag, as if it was provided. The usage
+// lists them together as "-color, -no-color".
+func NegatableBool(fs *flag.FlagSet, name string, value bool, usage string) *bool {
+	p := fs.Bool(name, value, usage)
+	fs.Var(&negatedValue{fs: fs, name: name}, "no-"+name, "")
+	return p
+}
+
+// negatedValue is the value of the -no-<name> form of a bool flag,
+// it sets the flag to the opposite when set.
+type negatedValue struct {
+	fs   *flag.FlagSet
+	name string
+}
+
+func (v *negatedValue) String() string {
+	return "false"
+}
+
+func (v *negatedValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	return v.fs.Set(v.name, strconv.FormatBool(!b))
+}
+
+func (v *negatedValue) IsBoolFlag() bool {
+	return true
+}
+
+// HideFlag hides the flags of fs with the names, e.g. debug knobs.
+// They are parsed, but neither listed in the usage nor completed.
+// It panics if a name is not defined.
+func HideFlag(fs *flag.FlagSet, names ...string) {
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			panic(fmt.Sprintf("command: hiding undefined flag -%s", name))
+		}
+		f.Value = &hiddenValue{valueWrapper{f.Value}}
+	}
+}
+
+// hiddenValue is the value of a hidden flag.
+type hiddenValue struct {
+	valueWrapper
+}
+
+// Reports whether the flag is hidden, or an alias or negated form
+// of a hidden flag of fs.
+func isHiddenFlag(fs *flag.FlagSet, f *flag.Flag) bool {
+	switch v := f.Value.(type) {
+	case *aliasValue:
+		return fs.Lookup(v.name) != nil && isHiddenFlag(fs, fs.Lookup(v.name))
+	case *negatedValue:
+		return fs.Lookup(v.name) != nil && isHiddenFlag(fs, fs.Lookup(v.name))
+	}
+	return wrapsValue(f.Value, func(v flag.Value) bool {
+		_, ok := v.(*hiddenValue)
+		return ok
+	})
+}
+
+// valueWrapper is embedded by the values wrapping the value of a
+// flag, e.g. by HideFlag, to forward IsBoolFlag and unwrap it.
+type valueWrapper struct {
+	flag.Value
+}
+
+func (w valueWrapper) IsBoolFlag() bool {
+	return isBoolValue(w.Value)
+}
+
+func (w valueWrapper) unwrap() flag.Value {
+	return w.Value
+}
+
+// Returns the name of the flag set by v if it's the value of another
+// name of the flag, which sets the flag instead of holding a value of
+// its own: an alias, the negated form of a bool flag or a deprecated
+// name. It returns an empty string otherwise.
+func auxTarget(v flag.Value) string {
+	switch v := v.(type) {
+	case *aliasValue:
+		return v.name
+	case *negatedValue:
+		return v.name
+	case *deprecatedValue:
+		if v.target != nil {
+			return v.target.Name
+		}
+	}
+	return ""
+}
+
+// Reports whether v is the value of another name of a flag.
+func isAuxFlag(v flag.Value) bool {
+	return auxTarget(v) != ""
+}
+
+// Returns the names of the flags of fs which are set, along with the
+// flags set through another name, e.g. -color for -no-color.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+		if name := auxTarget(f.Value); name != "" {
+			set[name] = true
+		}
+	})
+	return set
+}
+
+// Returns the innermost value wrapped by v, e.g. by HideFlag, or v
+// if it doesn't wrap one.
+func unwrapValue(v flag.Value) flag.Value {
+	for {
+		w, ok := v.(interface {
+			unwrap() flag.Value
+		})
+		if !ok {
+			return v
+		}
+		v = w.unwrap()
+	}
+}
+
+// Reports whether v, or a value wrapped by it, e.g. by HideFlag,
+// satisfies is.
+func wrapsValue(v flag.Value, is func(flag.Value) bool) bool {
+	for v != nil {
+		if is(v) {
+			return true
+		}
+		w, ok := v.(interface {
+			unwrap() flag.Value
+		})
+		if !ok {
+			return false
+		}
+		v = w.unwrap()
+	}
+	return false
+}
+
+// Prints the defaults of the flags of fs to its output like
+// fs.PrintDefaults, listing the aliases and the negated form of a
+// flag along with it, skipping the hidden flags and listing the
+// grouped flags under their groups.
+func printDefaults(fs *flag.FlagSet) {
+	aliases := make(map[string][]string)
+	negated := make(map[string]string)
+	hidden, grouped := false, false
+	fs.VisitAll(func(f *flag.Flag) {
+		switch v := f.Value.(type) {
+		case *aliasValue:
+			aliases[v.name] = append(aliases[v.name], f.Name)
+		case *negatedValue:
+			negated[v.name] = f.Name
+		}
+		hidden = hidden || isHiddenFlag(fs, f)
+		grouped = grouped || groupOf(f) != nil
+	})
+	if len(aliases) == 0 && len(negated) == 0 && !hidden && !grouped {
+		fs.PrintDefaults()
+		return
+	}
+	var groups []*groupValue
+	groupFlags := make(map[string][]*flag.Flag)
+	fs.VisitAll(func(f *flag.Flag) {
+		if isAuxFlag(f.Value) {
+			return
+		}
+		if isHiddenFlag(fs, f) {
+			return
+		}
+		g := groupOf(f)
+		if g == nil {
+			printFlag(fs, f, aliases, negated)
+			return
+		}
+		if _, ok := groupFlags[g.group]; !ok {
+			groups = append(groups, g)
+		}
+		groupFlags[g.group] = append(groupFlags[g.group], f)
+	})
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].order < groups[j].order
+	})
+	for _, g := range groups {
+		fmt.Fprintf(fs.Output(), "\n%s:\n", g.group)
+		for _, f := range groupFlags[g.group] {
+			printFlag(fs, f, aliases, negated)
+		}
+	}
+}
+
+// Prints the default of the flag like fs.PrintDefaults, along with
+// its aliases and negated form.
+func printFlag(fs *flag.FlagSet, f *flag.Flag, aliases map[string][]string, negated map[string]string) {
+	names := append(aliases[f.Name], f.Name)
+	if neg, ok := negated[f.Name]; ok {
+		names = append(names, neg)
+	}
+	var b strings.Builder
+	b.WriteString("  -" + strings.Join(names, ", -"))
+	// the type name is the one of the value wrapped, e.g. by
+	// GroupFlags.
+	name, usage := flag.UnquoteUsage(&flag.Flag{Name: f.Name, Usage: f.Usage, Value: unwrapValue(f.Value)})
+	if len(name) > 0 {
+		b.WriteString(" " + name)
+	}
+	if b.Len() <= 4 {
+		b.WriteString("\t")
+	} else {
+		b.WriteString("\n    \t")
+	}
+	b.WriteString(strings.ReplaceAll(usage, "\n", "\n    \t"))
+	switch f.DefValue {
+	case "", "0", "false":
+	default:
+		if name == "string" {
+			fmt.Fprintf(&b, " (default %q)", f.DefValue)
+		} else {
+			fmt.Fprintf(&b, " (default %v)", f.DefValue)
+		}
+	}
+	fmt.Fprintln(fs.Output(), b.String())
+}
+
+// Count defines an int flag incremented each time it's provided,
+// e.g. 3 for -v -v -v, or -vvv with grouped short flags. A value
+// attached with "=" sets the count, e.g. -v=2.
+func Count(fs *flag.FlagSet, name, usage string) *int {
+	v := new(countValue)
+	fs.Var(v, name, usage)
+	return (*int)(v)
+}
+
+type countValue int
+
+func (v *countValue) String() string {
+	if v == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*v))
+}
+
+func (v *countValue) Set(s string) error {
+	if s == "true" {
+		*v++
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = countValue(n)
+	return nil
+}
+
+// IsBoolFlag allows the flag to be provided without a value.
+func (v *countValue) IsBoolFlag() bool {
+	return true
+}
+
+// Choice defines a string flag which only accepts one of the choices,
+// e.g. "json", "yaml" or "table". The choices are listed in the usage
+// and completed.
+func Choice(fs *flag.FlagSet, name string, choices []string, value, usage string) *string {
+	v := &choiceValue{value: value, choices: choices}
+	fs.Var(v, name, fmt.Sprintf("%s (one of %s)", usage, strings.Join(choices, ", ")))
+	return &v.value
+}
+
+type choiceValue struct {
+	value   string
+	choices []string
+}
+
+func (v *choiceValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *choiceValue) Set(s string) error {
+	for _, choice := range v.choices {
+		if s == choice {
+			v.value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(v.choices, ", "))
+}
+
+// CompleteValue completes the choices starting with prefix.
+func (v *choiceValue) CompleteValue(prefix string) []string {
+	var matches []string
+	for _, choice := range v.choices {
+		if strings.HasPrefix(choice, prefix) {
+			matches = append(matches, choice)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,264 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests the values of an optional string flag.
+func TestOptionalString(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{}, ""},
+		{[]string{"-color"}, "always"},
+		{[]string{"-color=never"}, "never"},
+		{[]string{"-color", "arg"}, "always"},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		color := OptionalString(fs, "color", "always", "colorizes the output")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if *color != tt.expected {
+			t.Errorf("args %v: expected %q, found %q", tt.args, tt.expected, *color)
+		}
+	}
+}
+
+// Tests if a flag alias sets the flag and is listed along with it.
+func TestAlias(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "prints more")
+	fs.String("name", "x", "the name")
+	Alias(fs, "v", "verbose")
+	if err := fs.Parse([]string{"-v"}); err != nil {
+		t.Fatal(err)
+	}
+	if !*verbose {
+		t.Error("-verbose was expected to be set by -v")
+	}
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	printDefaults(fs)
+	want := "  -name string\n    \tthe name (default \"x\")\n  -v, -verbose\n    \tprints more\n"
+	if buf.String() != want {
+		t.Errorf("expected usage %q, found %q", want, buf.String())
+	}
+}
+
+// Tests if hidden flags are parsed but not listed.
+func TestHideFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	debug := fs.Bool("debug-dump", false, "dumps the state")
+	fs.String("name", "x", "the name")
+	HideFlag(fs, "debug-dump")
+	Alias(fs, "dd", "debug-dump")
+	if err := fs.Parse([]string{"-dd"}); err != nil {
+		t.Fatal(err)
+	}
+	if !*debug {
+		t.Error("-debug-dump was expected to be set")
+	}
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	printDefaults(fs)
+	want := "  -name string\n    \tthe name (default \"x\")\n"
+	if buf.String() != want {
+		t.Errorf("expected usage %q, found %q", want, buf.String())
+	}
+
+	resetForTesting()
+	On("command1", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.Bool("debug-dump", false, "")
+		fs.Bool("dry-run", false, "")
+		HideFlag(fs, "debug-dump")
+	}}, nil)
+	if found := defaultSet.complete([]string{"command1", "-d"}); !reflect.DeepEqual(found, []string{"-dry-run"}) {
+		t.Errorf("expected [-dry-run] to be completed, found %v", found)
+	}
+}
+
+// Tests if the negated form of a bool flag sets it to false.
+func TestNegatableBool(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	color := NegatableBool(fs, "color", true, "colors the output")
+	if err := fs.Parse([]string{"--no-color"}); err != nil {
+		t.Fatal(err)
+	}
+	if *color {
+		t.Error("-color was expected to be false")
+	}
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	printDefaults(fs)
+	want := "  -color, -no-color\n    \tcolors the output (default true)\n"
+	if buf.String() != want {
+		t.Errorf("expected usage %q, found %q", want, buf.String())
+	}
+}
+
+// Tests if a count flag counts its occurrences.
+func TestCount(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want int
+	}{
+		{nil, 0},
+		{[]string{"-v", "-v", "-v"}, 3},
+		{[]string{"-v", "-v=5", "-v"}, 6},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		v := Count(fs, "v", "verbosity")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if *v != tt.want {
+			t.Errorf("%v: expected %d, found %d", tt.args, tt.want, *v)
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	v := Count(fs, "v", "verbosity")
+	if err := fs.Parse(expandShortFlags(fs, []string{"-vvv"}, false)); err != nil {
+		t.Fatal(err)
+	}
+	if *v != 3 {
+		t.Errorf("expected 3 for -vvv, found %d", *v)
+	}
+}
+
+// Tests if a choice flag rejects other values.
+func TestChoice(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	format := Choice(fs, "format", []string{"json", "yaml", "table"}, "table", "output format")
+	if err := fs.Parse([]string{"-format", "yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if *format != "yaml" {
+		t.Errorf("expected yaml, found %q", *format)
+	}
+	if err := fs.Parse([]string{"-format", "xml"}); err == nil {
+		t.Error("an error was expected for an invalid choice")
+	}
+	if usage := fs.Lookup("format").Usage; usage != "output format (one of json, yaml, table)" {
+		t.Errorf("unexpected usage %q", usage)
+	}
+}
+
+// Tests if grouped flags are listed under their groups.
+func TestGroupFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("output", "", "output file")
+	fs.Bool("verbose", false, "prints more")
+	fs.String("host", "localhost", "server host")
+	fs.Int("port", 0, "server port")
+	GroupFlags(fs, "connection options", "port", "host")
+	GroupFlags(fs, "output options", "output")
+	Alias(fs, "o", "output")
+	if err := fs.Parse([]string{"-o", "out", "-port", "80"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	printDefaults(fs)
+	want := "  -verbose\n    \tprints more\n" +
+		"\nconnection options:\n  -host string\n    \tserver host (default \"localhost\")\n  -port int\n    \tserver port\n" +
+		"\noutput options:\n  -o, -output string\n    \toutput file\n"
+	if buf.String() != want {
+		t.Errorf("expected usage %q, found %q", want, buf.String())
+	}
+}
+
+// Tests if a flag set through its alias counts as provided.
+func TestAliasSetsFlag(t *testing.T) {
+	os.Setenv("T_C_PORT", "5")
+	defer os.Unsetenv("T_C_PORT")
+
+	var port *int
+	s := NewWithErrorHandling("t", flag.ContinueOnError)
+	s.SetIO(nil, ioutil.Discard, ioutil.Discard)
+	s.SetEnvPrefix("T")
+	s.On("c", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.Bool("verbose", false, "")
+		port = fs.Int("port", 0, "")
+		Alias(fs, "v", "verbose")
+		Alias(fs, "p", "port")
+		SetValidator(fs, "port", func(value string) error {
+			if value == "9" {
+				return errors.New("reserved")
+			}
+			return nil
+		})
+	}}, []string{"verbose"})
+	if err := s.ParseErr([]string{"c", "-v", "-p", "7"}); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 7 {
+		t.Errorf("expected the command line to override the environment, found %d", *port)
+	}
+	if source := s.FlagSource("port"); source != "flag" {
+		t.Errorf("expected the flag source, found %q", source)
+	}
+	if err := s.ParseErr([]string{"c", "-v", "-p", "9"}); err == nil {
+		t.Error("expected the validator to reject the value set through the alias")
+	}
+}
+
+// Tests if the negated form of a bool flag takes precedence over the
+// environment and the config file.
+func TestNegatableBoolPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"color": true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, env := range []string{"true", ""} {
+		os.Setenv("T_C_COLOR", env)
+		var color *bool
+		s := NewWithErrorHandling("t", flag.ContinueOnError)
+		s.SetIO(nil, ioutil.Discard, ioutil.Discard)
+		s.SetEnvPrefix("T")
+		s.EnableConfigFlag()
+		s.On("c", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+			color = NegatableBool(fs, "color", false, "")
+		}}, nil)
+		if err := s.ParseErr([]string{"-config", path, "c", "-no-color"}); err != nil {
+			t.Fatal(err)
+		}
+		if *color {
+			t.Errorf("T_C_COLOR=%q: expected -no-color to override the other sources", env)
+		}
+		if source := s.FlagSource("color"); source != "flag" {
+			t.Errorf("T_C_COLOR=%q: expected the flag source, found %q", env, source)
+		}
+	}
+	os.Unsetenv("T_C_COLOR")
+}
@@ -0,0 +1,168 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flagtypes defines flags of common types on a flag.FlagSet,
+// for use in the Flags of sub-commands. Durations are supported by
+// the flag package itself, see flag.Duration.
+package flagtypes
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// Time defines a flag parsing a time in the layout, e.g.
+// time.RFC3339.
+func Time(fs *flag.FlagSet, name, layout string, value time.Time, usage string) *time.Time {
+	v := &timeValue{t: value, layout: layout}
+	fs.Var(v, name, usage)
+	return &v.t
+}
+
+type timeValue struct {
+	t      time.Time
+	layout string
+}
+
+func (v *timeValue) String() string {
+	if v == nil || v.t.IsZero() {
+		return ""
+	}
+	return v.t.Format(v.layout)
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(v.layout, s)
+	if err != nil {
+		return err
+	}
+	v.t = t
+	return nil
+}
+
+// IP defines a flag parsing an IPv4 or IPv6 address.
+func IP(fs *flag.FlagSet, name string, value net.IP, usage string) *net.IP {
+	v := &ipValue{ip: value}
+	fs.Var(v, name, usage)
+	return &v.ip
+}
+
+type ipValue struct {
+	ip net.IP
+}
+
+func (v *ipValue) String() string {
+	if v == nil || v.ip == nil {
+		return ""
+	}
+	return v.ip.String()
+}
+
+func (v *ipValue) Set(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address %q", s)
+	}
+	v.ip = ip
+	return nil
+}
+
+// CIDR defines a flag parsing a network in CIDR notation, e.g.
+// 10.0.0.0/8.
+func CIDR(fs *flag.FlagSet, name string, value *net.IPNet, usage string) **net.IPNet {
+	v := &cidrValue{n: value}
+	fs.Var(v, name, usage)
+	return &v.n
+}
+
+type cidrValue struct {
+	n *net.IPNet
+}
+
+func (v *cidrValue) String() string {
+	if v == nil || v.n == nil {
+		return ""
+	}
+	return v.n.String()
+}
+
+func (v *cidrValue) Set(s string) error {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return err
+	}
+	v.n = n
+	return nil
+}
+
+// URL defines a flag parsing an absolute URL.
+func URL(fs *flag.FlagSet, name string, value *url.URL, usage string) **url.URL {
+	v := &urlValue{u: value}
+	fs.Var(v, name, usage)
+	return &v.u
+}
+
+type urlValue struct {
+	u *url.URL
+}
+
+func (v *urlValue) String() string {
+	if v == nil || v.u == nil {
+		return ""
+	}
+	return v.u.String()
+}
+
+func (v *urlValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if !u.IsAbs() {
+		return fmt.Errorf("URL %q is not absolute", s)
+	}
+	v.u = u
+	return nil
+}
+
+// Regexp defines a flag compiling a regular expression.
+func Regexp(fs *flag.FlagSet, name string, value *regexp.Regexp, usage string) **regexp.Regexp {
+	v := &regexpValue{re: value}
+	fs.Var(v, name, usage)
+	return &v.re
+}
+
+type regexpValue struct {
+	re *regexp.Regexp
+}
+
+func (v *regexpValue) String() string {
+	if v == nil || v.re == nil {
+		return ""
+	}
+	return v.re.String()
+}
+
+func (v *regexpValue) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+	v.re = re
+	return nil
+}
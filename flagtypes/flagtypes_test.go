@@ -0,0 +1,72 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flagtypes
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+// Tests if valid values are parsed.
+func TestValues(t *testing.T) {
+	fs := newFlagSet()
+	tm := Time(fs, "since", time.RFC3339, time.Time{}, "")
+	ip := IP(fs, "ip", nil, "")
+	cidr := CIDR(fs, "cidr", nil, "")
+	u := URL(fs, "url", nil, "")
+	re := Regexp(fs, "match", nil, "")
+	err := fs.Parse([]string{
+		"-since=2020-01-02T03:04:05Z",
+		"-ip=::1",
+		"-cidr=10.0.0.0/8",
+		"-url=https://example.com/x",
+		"-match=^a+$",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tm.Year() != 2020 || ip.String() != "::1" || (*cidr).String() != "10.0.0.0/8" || (*u).Host != "example.com" || !(*re).MatchString("aa") {
+		t.Errorf("unexpected values: %v %v %v %v %v", tm, ip, *cidr, *u, *re)
+	}
+}
+
+// Tests if invalid values are rejected.
+func TestInvalidValues(t *testing.T) {
+	for _, arg := range []string{
+		"-since=yesterday",
+		"-ip=1.2.3",
+		"-cidr=10.0.0.0",
+		"-url=/relative",
+		"-match=(",
+	} {
+		fs := newFlagSet()
+		Time(fs, "since", time.RFC3339, time.Time{}, "")
+		IP(fs, "ip", nil, "")
+		CIDR(fs, "cidr", nil, "")
+		URL(fs, "url", nil, "")
+		Regexp(fs, "match", nil, "")
+		if err := fs.Parse([]string{arg}); err == nil {
+			t.Errorf("%s: an error was expected", arg)
+		}
+	}
+}
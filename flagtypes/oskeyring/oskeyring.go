@@ -0,0 +1,45 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oskeyring stores the secrets of flagtypes.Secret flags in
+// the credential store of the OS: the Keychain on macOS, the Secret
+// Service through libsecret on Linux and the Credential Manager on
+// Windows.
+//
+//	token := flagtypes.Secret(fs, "token", "API token",
+//		flagtypes.SecretKeyring(oskeyring.Keyring{}, "mytool"))
+package oskeyring
+
+import (
+	"github.com/rakyll/command/flagtypes"
+	"github.com/zalando/go-keyring"
+)
+
+// Keyring is the credential store of the OS.
+type Keyring struct{}
+
+// Get returns the secret of the user of the service,
+// flagtypes.ErrSecretNotFound if there is none.
+func (Keyring) Get(service, user string) (string, error) {
+	secret, err := keyring.Get(service, user)
+	if err == keyring.ErrNotFound {
+		return "", flagtypes.ErrSecretNotFound
+	}
+	return secret, err
+}
+
+// Set stores the secret of the user of the service.
+func (Keyring) Set(service, user, secret string) error {
+	return keyring.Set(service, user, secret)
+}
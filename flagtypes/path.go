@@ -0,0 +1,134 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flagtypes
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// PathCheck is a set of checks of a path flag run at parse time.
+type PathCheck int
+
+const (
+	// MustExist requires the path to exist.
+	MustExist PathCheck = 1 << iota
+	// MustNotExist requires the path not to exist.
+	MustNotExist
+	// Readable requires the path to be readable if it exists.
+	Readable
+	// Writable requires the path, or its parent directory if it
+	// doesn't exist, to be writable.
+	Writable
+)
+
+// FilePath defines a flag for a file path, rejecting directories and
+// the paths failing the checks.
+func FilePath(fs *flag.FlagSet, name, value string, checks PathCheck, usage string) *string {
+	v := &pathValue{path: value, checks: checks}
+	fs.Var(v, name, usage)
+	return &v.path
+}
+
+// DirPath defines a flag for a directory path, rejecting files and
+// the paths failing the checks.
+func DirPath(fs *flag.FlagSet, name, value string, checks PathCheck, usage string) *string {
+	v := &pathValue{path: value, checks: checks, dir: true}
+	fs.Var(v, name, usage)
+	return &v.path
+}
+
+type pathValue struct {
+	path   string
+	checks PathCheck
+	dir    bool
+}
+
+func (v *pathValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.path
+}
+
+func (v *pathValue) Set(s string) error {
+	if err := v.check(s); err != nil {
+		return err
+	}
+	v.path = s
+	return nil
+}
+
+// Returns the error of the first check failed by path.
+func (v *pathValue) check(path string) error {
+	kind := "file"
+	if v.dir {
+		kind = "directory"
+	}
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+	switch {
+	case !exists && v.checks&MustExist != 0:
+		return fmt.Errorf("%s %q does not exist", kind, path)
+	case exists && v.checks&MustNotExist != 0:
+		return fmt.Errorf("%s %q already exists", kind, path)
+	case exists && info.IsDir() != v.dir:
+		return fmt.Errorf("%q is not a %s", path, kind)
+	}
+	if exists && v.checks&Readable != 0 {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("%s %q is not readable", kind, path)
+		}
+		f.Close()
+	}
+	if v.checks&Writable != 0 {
+		if err := writable(path, exists && v.dir, exists); err != nil {
+			return fmt.Errorf("%s %q is not writable", kind, path)
+		}
+	}
+	return nil
+}
+
+// Checks if path can be written, by opening the file or creating a
+// temporary file in the directory.
+func writable(path string, dir, exists bool) error {
+	if exists && !dir {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	if !exists {
+		path = filepath.Dir(path)
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return errors.New("no parent directory")
+		}
+	}
+	f, err := ioutil.TempFile(path, ".writable")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
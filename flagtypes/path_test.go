@@ -0,0 +1,68 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flagtypes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests if path flags are checked at parse time.
+func TestPathChecks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flagtypes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		dir    bool
+		checks PathCheck
+		path   string
+		err    string
+	}{
+		{false, MustExist | Readable, file, ""},
+		{false, MustExist, missing, "does not exist"},
+		{false, MustNotExist, file, "already exists"},
+		{false, MustNotExist | Writable, missing, ""},
+		{false, 0, dir, "is not a file"},
+		{true, MustExist | Writable, dir, ""},
+		{true, 0, file, "is not a directory"},
+		{false, Writable, filepath.Join(missing, "file"), "is not writable"},
+	}
+	for _, tt := range tests {
+		fs := newFlagSet()
+		if tt.dir {
+			DirPath(fs, "path", "", tt.checks, "")
+		} else {
+			FilePath(fs, "path", "", tt.checks, "")
+		}
+		err := fs.Parse([]string{"-path", tt.path})
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: error = %v, want %q", tt.path, err, tt.err)
+		}
+	}
+}
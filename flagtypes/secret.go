@@ -0,0 +1,202 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flagtypes
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// SecretValue is the value of a Secret flag. Its value is never
+// printed, neither as a default in usage nor by String.
+type SecretValue struct {
+	name   string
+	value  string
+	set    bool
+	env    string
+	file   string
+	prompt string
+
+	keyring Keyring
+	service string
+
+	// the streams the secret is prompted on.
+	in  io.Reader
+	out io.Writer
+}
+
+// SecretOption configures a Secret flag.
+type SecretOption func(*SecretValue)
+
+// SecretEnv reads the secret from the environment variable if the
+// flag is not set.
+func SecretEnv(key string) SecretOption {
+	return func(v *SecretValue) { v.env = key }
+}
+
+// SecretFile reads the secret from the file if the flag is not set,
+// e.g. a mounted /run/secrets/token. Trailing newlines are trimmed.
+func SecretFile(path string) SecretOption {
+	return func(v *SecretValue) { v.file = path }
+}
+
+// Keyring is a credential store secrets can be read from and stored
+// in, e.g. the macOS Keychain, libsecret or the Windows Credential
+// Manager as implemented by the oskeyring package. Secrets are
+// identified by a service name and a user, the flag name.
+type Keyring interface {
+	// Get returns the secret, ErrSecretNotFound if there is none.
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+}
+
+// ErrSecretNotFound is returned by a Keyring if it has no secret
+// for a service and user.
+var ErrSecretNotFound = errors.New("flagtypes: secret not found")
+
+// SecretKeyring reads the secret from the keyring if it's neither
+// set by the flag, the environment nor the file, and before prompting
+// for it. Store saves it there.
+func SecretKeyring(kr Keyring, service string) SecretOption {
+	return func(v *SecretValue) { v.keyring, v.service = kr, service }
+}
+
+// SecretPrompt sets the prompt asking for the secret, "<name>: " by
+// default.
+func SecretPrompt(prompt string) SecretOption {
+	return func(v *SecretValue) { v.prompt = prompt }
+}
+
+// Secret defines a flag for a secret, e.g. a password or token.
+// If the flag is not set, Get reads it from the environment variable,
+// file or keyring set by the options, or prompts for it on the
+// terminal without echoing.
+func Secret(fs *flag.FlagSet, name, usage string, opts ...SecretOption) *SecretValue {
+	v := &SecretValue{name: name, prompt: name + ": "}
+	for _, opt := range opts {
+		opt(v)
+	}
+	fs.Var(v, name, usage)
+	return v
+}
+
+func (v *SecretValue) String() string {
+	return ""
+}
+
+func (v *SecretValue) Set(s string) error {
+	v.value, v.set = s, true
+	return nil
+}
+
+// IsSecret marks the value as a secret, redacted by the config dump
+// sub-command of the command package.
+func (v *SecretValue) IsSecret() bool {
+	return true
+}
+
+// SetIO sets the streams the secret is prompted on, stdin and
+// stderr by default. The command package calls it with the streams
+// of the set the flag is parsed by.
+func (v *SecretValue) SetIO(in io.Reader, out, err io.Writer) {
+	v.in, v.out = in, err
+}
+
+// Reports whether r is a terminal and reads a line from it without
+// echoing.
+var (
+	isTerminal = func(r io.Reader) bool {
+		f, ok := r.(*os.File)
+		return ok && term.IsTerminal(int(f.Fd()))
+	}
+	readPassword = func(r io.Reader) ([]byte, error) {
+		return term.ReadPassword(int(r.(*os.File).Fd()))
+	}
+)
+
+// Get returns the secret, from the flag, the environment, the file,
+// the keyring or the terminal in that order. The prompt result is
+// kept, it is only asked once. It fails if the secret is not set and
+// stdin is not a terminal.
+func (v *SecretValue) Get() (string, error) {
+	if v.set {
+		return v.value, nil
+	}
+	if v.env != "" {
+		if s, ok := os.LookupEnv(v.env); ok {
+			return s, nil
+		}
+	}
+	if v.file != "" {
+		b, err := ioutil.ReadFile(v.file)
+		if err == nil {
+			return strings.TrimRight(string(b), "\r\n"), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	if v.keyring != nil {
+		secret, err := v.keyring.Get(v.service, v.name)
+		if err == nil {
+			return secret, nil
+		}
+		if err != ErrSecretNotFound {
+			return "", err
+		}
+	}
+	in, out := v.in, v.out
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+	if !isTerminal(in) {
+		return "", fmt.Errorf("no value for secret -%s", v.name)
+	}
+	fmt.Fprint(out, v.prompt)
+	b, err := readPassword(in)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+	if len(b) == 0 {
+		return "", errors.New("empty secret")
+	}
+	v.Set(string(b))
+	return v.value, nil
+}
+
+// Store saves the secret to the keyring, e.g. once a login with it
+// succeeds, so later runs don't need it. It fails if no keyring is
+// set.
+func (v *SecretValue) Store() error {
+	if v.keyring == nil {
+		return fmt.Errorf("no keyring for secret -%s", v.name)
+	}
+	secret, err := v.Get()
+	if err != nil {
+		return err
+	}
+	return v.keyring.Set(v.service, v.name, secret)
+}
@@ -0,0 +1,153 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flagtypes
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests if the secret is resolved from the flag, env, file and prompt.
+func TestSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flagtypes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("FLAGTYPES_TOKEN", "from-env")
+	defer os.Unsetenv("FLAGTYPES_TOKEN")
+	defer func(terminal func(io.Reader) bool, read func(io.Reader) ([]byte, error)) {
+		isTerminal, readPassword = terminal, read
+	}(isTerminal, readPassword)
+	prompts := 0
+	isTerminal = func(io.Reader) bool { return true }
+	readPassword = func(io.Reader) ([]byte, error) {
+		prompts++
+		return []byte("from-prompt"), nil
+	}
+
+	tests := []struct {
+		args []string
+		opts []SecretOption
+		want string
+	}{
+		{[]string{"-token=from-flag"}, []SecretOption{SecretEnv("FLAGTYPES_TOKEN")}, "from-flag"},
+		{nil, []SecretOption{SecretEnv("FLAGTYPES_TOKEN"), SecretFile(file)}, "from-env"},
+		{nil, []SecretOption{SecretEnv("FLAGTYPES_MISSING"), SecretFile(file)}, "from-file"},
+		{nil, []SecretOption{SecretFile(filepath.Join(dir, "missing"))}, "from-prompt"},
+	}
+	for _, tt := range tests {
+		fs := newFlagSet()
+		secret := Secret(fs, "token", "", tt.opts...)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if got, err := secret.Get(); err != nil || got != tt.want {
+				t.Errorf("Get() = %q, %v; want %q", got, err, tt.want)
+			}
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("prompted %d times, want once", prompts)
+	}
+
+	isTerminal = func(io.Reader) bool { return false }
+	if _, err := Secret(newFlagSet(), "token", "").Get(); err == nil {
+		t.Error("an error was expected without a terminal")
+	}
+}
+
+// Tests if the secret is prompted on the streams set by SetIO.
+func TestSecretSetIO(t *testing.T) {
+	defer func(terminal func(io.Reader) bool, read func(io.Reader) ([]byte, error)) {
+		isTerminal, readPassword = terminal, read
+	}(isTerminal, readPassword)
+	in := bytes.NewBufferString("from-prompt\n")
+	var out bytes.Buffer
+	isTerminal = func(r io.Reader) bool { return r == in }
+	readPassword = func(r io.Reader) ([]byte, error) {
+		b, err := r.(*bytes.Buffer).ReadBytes('\n')
+		return bytes.TrimSuffix(b, []byte("\n")), err
+	}
+
+	secret := Secret(newFlagSet(), "token", "")
+	secret.SetIO(in, nil, &out)
+	if got, err := secret.Get(); err != nil || got != "from-prompt" {
+		t.Errorf("Get() = %q, %v; want %q", got, err, "from-prompt")
+	}
+	if out.String() != "token: \n" {
+		t.Errorf("unexpected prompt %q", out.String())
+	}
+}
+
+// Tests if the secret is not printed in usage.
+func TestSecretUsage(t *testing.T) {
+	fs := newFlagSet()
+	Secret(fs, "token", "API token")
+	fs.Parse([]string{"-token=hunter2"})
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	fs.PrintDefaults()
+	if bytes.Contains(buf.Bytes(), []byte("hunter2")) {
+		t.Errorf("usage leaks the secret: %s", buf.String())
+	}
+}
+
+// Tests if the secret is read from and stored in the keyring.
+func TestSecretKeyring(t *testing.T) {
+	defer func(terminal func(io.Reader) bool) { isTerminal = terminal }(isTerminal)
+	isTerminal = func(io.Reader) bool { return false }
+
+	kr := mapKeyring{}
+	fs := newFlagSet()
+	secret := Secret(fs, "token", "", SecretKeyring(kr, "tool"))
+	if _, err := secret.Get(); err == nil {
+		t.Error("an error was expected without a stored secret")
+	}
+	if err := fs.Parse([]string{"-token=s3cr3t"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := secret.Store(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Secret(newFlagSet(), "token", "", SecretKeyring(kr, "tool")).Get(); err != nil || got != "s3cr3t" {
+		t.Errorf("Get() = %q, %v; want %q", got, err, "s3cr3t")
+	}
+}
+
+// mapKeyring is an in-memory Keyring.
+type mapKeyring map[string]string
+
+func (kr mapKeyring) Get(service, user string) (string, error) {
+	secret, ok := kr[service+"/"+user]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func (kr mapKeyring) Set(service, user, secret string) error {
+	kr[service+"/"+user] = secret
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flagtypes
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Size units, the ones without an "i" are decimal.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"EiB", 1 << 60}, {"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"EB", 1e18}, {"PB", 1e15}, {"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+}
+
+// Size defines a flag parsing a byte size in human units, e.g. 512K,
+// 10MiB or 1.5GB. K, M, G, T, P and E are decimal units, Ki, Mi, Gi,
+// Ti, Pi and Ei binary ones, the B suffix is optional. The default is
+// printed in the largest unit dividing it.
+func Size(fs *flag.FlagSet, name string, value int64, usage string) *int64 {
+	v := sizeValue(value)
+	fs.Var(&v, name, usage)
+	return (*int64)(&v)
+}
+
+type sizeValue int64
+
+func (v *sizeValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return FormatSize(int64(*v))
+}
+
+func (v *sizeValue) Set(s string) error {
+	n, err := ParseSize(s)
+	if err != nil {
+		return err
+	}
+	*v = sizeValue(n)
+	return nil
+}
+
+// ParseSize parses a byte size in the units of Size.
+func ParseSize(s string) (int64, error) {
+	str := strings.TrimSpace(s)
+	num, unit := str, int64(1)
+	upper := strings.ToUpper(str)
+	for _, u := range sizeUnits {
+		for _, suffix := range []string{u.suffix, strings.TrimSuffix(u.suffix, "B")} {
+			if strings.HasSuffix(upper, strings.ToUpper(suffix)) {
+				num, unit = str[:len(str)-len(suffix)], u.bytes
+				break
+			}
+		}
+		if unit != 1 {
+			break
+		}
+	}
+	if unit == 1 {
+		num = strings.TrimSuffix(upper, "B")
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if f*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return int64(f * float64(unit)), nil
+}
+
+// FormatSize formats a byte size in the largest unit dividing it.
+func FormatSize(n int64) string {
+	if n == 0 {
+		return "0B"
+	}
+	for _, u := range sizeUnits {
+		if n%u.bytes == 0 {
+			return strconv.FormatInt(n/u.bytes, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
@@ -0,0 +1,60 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flagtypes
+
+import "testing"
+
+// Tests if sizes in human units are parsed.
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"0":      0,
+		"100":    100,
+		"100B":   100,
+		"512K":   512000,
+		"512KiB": 512 << 10,
+		"10MiB":  10 << 20,
+		"10mi":   10 << 20,
+		"1.5GB":  1500000000,
+		"2T":     2e12,
+	} {
+		got, err := ParseSize(s)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "K", "-1M", "1X", "10EiB"} {
+		if _, err := ParseSize(s); err == nil {
+			t.Errorf("ParseSize(%q): an error was expected", s)
+		}
+	}
+}
+
+// Tests if the default is printed in human units.
+func TestSizeDefault(t *testing.T) {
+	fs := newFlagSet()
+	size := Size(fs, "cache", 64<<20, "")
+	if got := fs.Lookup("cache").DefValue; got != "64MiB" {
+		t.Errorf("DefValue = %q, want 64MiB", got)
+	}
+	if err := fs.Parse([]string{"-cache=1.5GB"}); err != nil {
+		t.Fatal(err)
+	}
+	if *size != 1500000000 {
+		t.Errorf("size = %d", *size)
+	}
+	if got := FormatSize(1500); got != "1500B" {
+		t.Errorf("FormatSize(1500) = %q", got)
+	}
+}
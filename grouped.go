@@ -0,0 +1,74 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strings"
+)
+
+// SetGroupedShortFlags sets whether single-letter bool flags of the
+// sub-commands can be grouped, e.g. -rf for -r -f. A group is only
+// expanded if the sub-command doesn't define a flag with its name.
+func SetGroupedShortFlags(grouped bool) {
+	defaultSet.SetGroupedShortFlags(grouped)
+}
+
+// SetGroupedShortFlags sets whether single-letter bool flags of the
+// sub-commands of the set can be grouped.
+func (s *CommandSet) SetGroupedShortFlags(grouped bool) {
+	s.groupedShortFlags = grouped
+}
+
+// Returns the arguments with the groups of single-letter bool flags
+// of fs expanded. Positionals are only scanned if interspersed.
+func expandShortFlags(fs *flag.FlagSet, arguments []string, interspersed bool) []string {
+	var expanded []string
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		if arg == "--" || (!interspersed && (len(arg) < 2 || arg[0] != '-')) {
+			return append(expanded, arguments[i:]...)
+		}
+		if group, ok := shortFlagGroup(fs, arg); ok {
+			expanded = append(expanded, group...)
+			continue
+		}
+		expanded = append(expanded, arg)
+		name := strings.TrimLeft(arg, "-")
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f) && i+1 < len(arguments) {
+			// skip the value.
+			i++
+			expanded = append(expanded, arguments[i])
+		}
+	}
+	return expanded
+}
+
+// Returns the flags of arg if it's a group of single-letter bool
+// flags of fs.
+func shortFlagGroup(fs *flag.FlagSet, arg string) ([]string, bool) {
+	if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' || strings.Contains(arg, "=") || fs.Lookup(arg[1:]) != nil {
+		return nil, false
+	}
+	var group []string
+	for _, r := range arg[1:] {
+		f := fs.Lookup(string(r))
+		if f == nil || !isBoolFlag(f) {
+			return nil, false
+		}
+		group = append(group, "-"+string(r))
+	}
+	return group, true
+}
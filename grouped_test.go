@@ -0,0 +1,66 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+// Tests if groups of single-letter bool flags are expanded.
+func TestExpandShortFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("r", false, "")
+	fs.Bool("f", false, "")
+	fs.Bool("rf2", false, "")
+	fs.String("o", "", "")
+	for _, tt := range []struct {
+		args         []string
+		interspersed bool
+		want         []string
+	}{
+		{[]string{"-rf", "a"}, false, []string{"-r", "-f", "a"}},
+		{[]string{"-rf2", "-o", "-rf", "-fx"}, false, []string{"-rf2", "-o", "-rf", "-fx"}},
+		{[]string{"a", "-rf"}, false, []string{"a", "-rf"}},
+		{[]string{"a", "-rf", "--", "-fr"}, true, []string{"a", "-r", "-f", "--", "-fr"}},
+	} {
+		if found := expandShortFlags(fs, tt.args, tt.interspersed); !reflect.DeepEqual(found, tt.want) {
+			t.Errorf("%v: expected %v, found %v", tt.args, tt.want, found)
+		}
+	}
+}
+
+// Tests if grouped flags of a sub-command are parsed.
+func TestGroupedShortFlags(t *testing.T) {
+	resetForTesting("command1", "-rf")
+
+	On("command1", "", &groupCmd{}, nil)
+	SetGroupedShortFlags(true)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// groupCmd is a test sub command with single-letter flags.
+type groupCmd struct {
+	testCmd1
+}
+
+func (cmd *groupCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	fs.Bool("r", false, "")
+	fs.Bool("f", false, "")
+	return fs
+}
@@ -0,0 +1,104 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"sort"
+)
+
+// CommandInfo describes a registered sub-command.
+type CommandInfo struct {
+	Name          string
+	Aliases       []string
+	Description   string
+	RequiredFlags []string
+	Args          []string
+	Hidden        bool
+	Annotations   map[string]string
+	Long          string
+	Examples      []string
+	SeeAlso       []string
+	Owner         string
+}
+
+// Annotate attaches an arbitrary key/value annotation to the
+// sub-command. The package doesn't interpret annotations, they are
+// available to tooling through Commands and Lookup.
+func Annotate(key, value string) Option {
+	return func(cont *cmdCont) {
+		if cont.annotations == nil {
+			cont.annotations = make(map[string]string)
+		}
+		cont.annotations[key] = value
+	}
+}
+
+// Commands returns the registered sub-commands sorted by name,
+// including the hidden ones.
+func Commands() []CommandInfo {
+	return defaultSet.Commands()
+}
+
+// Commands returns the sub-commands registered in the set.
+func (s *CommandSet) Commands() []CommandInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var infos []CommandInfo
+	for name, cont := range s.cmds {
+		if name == cont.name {
+			infos = append(infos, cont.info())
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// Lookup returns the named sub-command.
+func Lookup(name string) (CommandInfo, bool) {
+	return defaultSet.Lookup(name)
+}
+
+// Lookup returns the named sub-command of the set.
+func (s *CommandSet) Lookup(name string) (CommandInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cont, ok := s.cmds[name]
+	if !ok {
+		return CommandInfo{}, false
+	}
+	return cont.info(), true
+}
+
+func (cont *cmdCont) info() CommandInfo {
+	annotations := make(map[string]string, len(cont.annotations))
+	for k, v := range cont.annotations {
+		annotations[k] = v
+	}
+	return CommandInfo{
+		Name:          cont.name,
+		Aliases:       cont.aliases,
+		Description:   cont.desc,
+		RequiredFlags: cont.requiredFlags,
+		Args:          cont.args,
+		Hidden:        cont.hidden,
+		Annotations:   annotations,
+		Long:          cont.long,
+		Examples:      cont.examples,
+		SeeAlso:       cont.seeAlso,
+		Owner:         cont.owner,
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests if annotations are exposed through introspection.
+func TestAnnotations(t *testing.T) {
+	resetForTesting()
+
+	On("command2", "", &testCmd2{}, []string{})
+	On("command1", "Description about command1", &testCmd1{}, []string{}, Annotate("owner", "infra"), Annotate("stability", "beta"))
+	info, ok := Lookup("command1")
+	if !ok {
+		t.Fatal("command 'command1' was expected to be found")
+	}
+	if info.Description != "Description about command1" {
+		t.Errorf("unexpected description %q", info.Description)
+	}
+	if info.Annotations["owner"] != "infra" || info.Annotations["stability"] != "beta" {
+		t.Errorf("unexpected annotations %v", info.Annotations)
+	}
+	if infos := Commands(); len(infos) != 2 || infos[0].Name != "command1" {
+		t.Errorf("expected command1 and command2, found %v", infos)
+	}
+}
+
+// Tests if hidden sub-commands run, but are omitted from the usage
+// and completion.
+func TestHidden(t *testing.T) {
+	resetForTesting("debug")
+
+	c1 := &testCmd1{}
+	On("debug", "", c1, nil, Hidden())
+	On("deploy", "", &testCmd2{}, nil)
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run {
+		t.Error("the hidden command was expected to run")
+	}
+	if strings.Contains(UsageString(), "debug") {
+		t.Error("the hidden command was not expected in the usage")
+	}
+	if found := defaultSet.complete([]string{"de"}); len(found) != 1 || found[0] != "deploy" {
+		t.Errorf("unexpected completions: %v", found)
+	}
+	if info, _ := Lookup("debug"); !info.Hidden {
+		t.Error("the command was expected to be reported as hidden")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+)
+
+// SetInterspersed sets whether the flags of the sub-commands can
+// follow their positional arguments, e.g. `program cp a -r b`.
+// Flag parsing still stops at a "--", and the positional arguments
+// are passed to the runnable in order.
+func SetInterspersed(interspersed bool) {
+	defaultSet.SetInterspersed(interspersed)
+}
+
+// SetInterspersed sets whether the sub-command flags of the set can
+// follow their positional arguments.
+func (s *CommandSet) SetInterspersed(interspersed bool) {
+	s.interspersed = interspersed
+}
+
+// Parses the flags of fs among the positional arguments, and returns
+// the positional arguments and the ones following a "--".
+func parseInterspersed(fs *flag.FlagSet, arguments []string) (args, passthrough []string, err error) {
+	positionals := []string{}
+	for {
+		if err := fs.Parse(arguments); err != nil {
+			return nil, nil, err
+		}
+		rest := fs.Args()
+		if passthrough := terminated(arguments, rest); passthrough != nil {
+			return append(positionals, rest...), passthrough, nil
+		}
+		if len(rest) == 0 {
+			return positionals, nil, nil
+		}
+		positionals = append(positionals, rest[0])
+		arguments = rest[1:]
+	}
+}
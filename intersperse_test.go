@@ -0,0 +1,47 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests if flags following positionals are parsed in interspersed
+// mode.
+func TestInterspersed(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		wantArgs []string
+	}{
+		{[]string{"command1", "a", "-flag1", "b"}, []string{"a", "b"}},
+		{[]string{"command1", "-flag1", "a", "b"}, []string{"a", "b"}},
+		{[]string{"command1", "a", "-flag1", "--", "-b"}, []string{"a", "-b"}},
+	} {
+		resetForTesting(tt.args...)
+		c1 := &testCmd1{}
+		On("command1", "", c1, nil)
+		SetInterspersed(true)
+		if err := ParseErr(); err != nil {
+			t.Fatal(err)
+		}
+		if !*c1.flag1 {
+			t.Errorf("%v: -flag1 was expected to be set", tt.args)
+		}
+		if args := parsedArgs(); !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%v: expected args %v, found %v", tt.args, tt.wantArgs, args)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"io"
+	"os"
+)
+
+// IOCmd is implemented by sub-commands that read and write the
+// streams of their set instead of the process' standard streams.
+// SetIO is called with the streams before the sub-command runs.
+type IOCmd interface {
+	Cmd
+	SetIO(in io.Reader, out, err io.Writer)
+}
+
+// SetIO sets the streams usage guides, errors and prompts are written
+// to and passed to sub-commands implementing IOCmd. Flag values with
+// a SetIO method like IOCmd's, e.g. ValueFromFile reading "-", are
+// passed them too. A nil stream stays the standard one, or the stream
+// of the parent set if the set is nested.
+func SetIO(in io.Reader, out, err io.Writer) {
+	defaultSet.SetIO(in, out, err)
+}
+
+// SetIO sets the streams of the set.
+func (s *CommandSet) SetIO(in io.Reader, out, err io.Writer) {
+	s.in, s.out, s.err = in, out, err
+}
+
+// Streams sets the streams passed to the sub-command if it implements
+// IOCmd, overriding the streams of its set. A nil stream stays the
+// stream of the set.
+func Streams(in io.Reader, out, err io.Writer) Option {
+	return func(cont *cmdCont) {
+		cont.in, cont.out, cont.err = in, out, err
+	}
+}
+
+func (s *CommandSet) stdin() io.Reader {
+	switch {
+	case s.in != nil:
+		return s.in
+	case s.parent != nil:
+		return s.parent.stdin()
+	}
+	return stdin
+}
+
+func (s *CommandSet) stdout() io.Writer {
+	switch {
+	case s.out != nil:
+		return s.out
+	case s.parent != nil:
+		return s.parent.stdout()
+	}
+	return os.Stdout
+}
+
+func (s *CommandSet) stderr() io.Writer {
+	switch {
+	case s.err != nil:
+		return s.err
+	case s.parent != nil:
+		return s.parent.stderr()
+	}
+	return os.Stderr
+}
+
+// Returns the streams of the sub-command.
+func (s *CommandSet) streams(cont *cmdCont) (in io.Reader, out, err io.Writer) {
+	in, out, err = s.stdin(), s.stdout(), s.stderr()
+	if cont.in != nil {
+		in = cont.in
+	}
+	if cont.out != nil {
+		out = cont.out
+	}
+	if cont.err != nil {
+		err = cont.err
+	}
+	return
+}
+
+// ioValue is implemented by the flag values reading or writing the
+// streams of the set, e.g. by ValueFromFile.
+type ioValue interface {
+	SetIO(in io.Reader, out, err io.Writer)
+}
+
+// Passes the streams to the values of fs, or the values wrapped by
+// them, implementing ioValue.
+func setValueIO(fs *flag.FlagSet, in io.Reader, out, err io.Writer) {
+	fs.VisitAll(func(f *flag.Flag) {
+		wrapsValue(f.Value, func(v flag.Value) bool {
+			if v, ok := v.(ioValue); ok {
+				v.SetIO(in, out, err)
+			}
+			return false
+		})
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+// ioCmd is a test sub command writing to its streams.
+type ioCmd struct {
+	testCmd1
+
+	out io.Writer
+}
+
+func (cmd *ioCmd) SetIO(in io.Reader, out, err io.Writer) {
+	cmd.out = out
+}
+
+func (cmd *ioCmd) Run(args []string) {
+	io.WriteString(cmd.out, "hello")
+}
+
+// Tests if usage guides and sub-commands use the streams of the set.
+func TestSetIO(t *testing.T) {
+	var out, errOut, cmdOut bytes.Buffer
+	set := NewWithErrorHandling("tool", flag.ContinueOnError)
+	set.SetIO(nil, &out, &errOut)
+	set.On("io", "", &ioCmd{}, nil)
+	set.On("other", "", &ioCmd{}, nil, Streams(nil, &cmdOut, nil))
+	set.ParseAndRun([]string{"io"})
+	if out.String() != "hello" {
+		t.Errorf("expected the output of the set, found %q", out.String())
+	}
+	set.ParseAndRun([]string{"other"})
+	if cmdOut.String() != "hello" {
+		t.Errorf("expected the output of the command, found %q", cmdOut.String())
+	}
+	set.ParseAndRun([]string{"unknown"})
+	if !strings.Contains(errOut.String(), "Usage: tool <command>") {
+		t.Errorf("expected the usage in the error stream, found %q", errOut.String())
+	}
+}
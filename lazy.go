@@ -0,0 +1,38 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+// OnLazy registers the Cmd built by factory for the provided
+// sub-command name like On. The factory is called once, the first
+// time the sub-command is parsed, run, completed or its usage is
+// printed.
+func OnLazy(name, description string, factory func() Cmd, requiredFlags []string, opts ...Option) {
+	defaultSet.OnLazy(name, description, factory, requiredFlags, opts...)
+}
+
+// OnLazy registers a lazily built Cmd in the set.
+func (s *CommandSet) OnLazy(name, description string, factory func() Cmd, requiredFlags []string, opts ...Option) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.on(name, description, nil, requiredFlags, opts...).factory = factory
+}
+
+// Returns the Cmd of the sub-command, building it if it's lazy.
+func (cont *cmdCont) cmd() Cmd {
+	if cont.factory != nil {
+		cont.command, cont.factory = cont.factory(), nil
+	}
+	return cont.command
+}
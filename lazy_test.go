@@ -0,0 +1,60 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"testing"
+)
+
+// Tests if lazy sub-commands are only built once they are selected.
+func TestOnLazy(t *testing.T) {
+	resetForTesting("command1", "-flag1")
+
+	built := map[string]int{}
+	c1 := &testCmd1{}
+	OnLazy("command1", "", func() Cmd { built["command1"]++; return c1 }, []string{"flag1"})
+	OnLazy("command2", "", func() Cmd { built["command2"]++; return &testCmd2{} }, nil)
+	UsageString()
+	if len(built) != 0 {
+		t.Errorf("no commands were expected to be built for the usage, found %v", built)
+	}
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run || !*c1.flag1 {
+		t.Error("command 'command1' was expected to run with -flag1")
+	}
+	if built["command1"] != 1 || built["command2"] != 0 {
+		t.Errorf("only command1 was expected to be built once, found %v", built)
+	}
+}
+
+// Tests if the commands of a resolver don't build lazy sub-commands.
+func TestOnLazyResolver(t *testing.T) {
+	resetForTesting("deploy@v2")
+
+	built := 0
+	OnLazy("deploy", "", func() Cmd { built++; return &testCmd1{} }, nil)
+	v2 := &testCmd2{}
+	SetResolver(func(args []string) (Cmd, []string, error) {
+		return v2, args[1:], nil
+	})
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !v2.run || built != 0 {
+		t.Errorf("only 'deploy@v2' was expected to run, 'deploy' was built %d times", built)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StringMap defines a flag which can be repeated with key=value
+// pairs, e.g. -label env=prod -label team=infra, collecting them in
+// the returned map. The map is initialized with value, which is
+// listed as the default in the usage.
+func StringMap(fs *flag.FlagSet, name string, value map[string]string, usage string) map[string]string {
+	m := make(map[string]string, len(value))
+	for k, v := range value {
+		m[k] = v
+	}
+	fs.Var(stringMap(m), name, usage+" (key=value, can be repeated)")
+	return m
+}
+
+type stringMap map[string]string
+
+func (m stringMap) String() string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + m[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m stringMap) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i < 1 {
+		return fmt.Errorf("malformed pair %q, expected key=value", s)
+	}
+	m[s[:i]] = s[i+1:]
+	return nil
+}
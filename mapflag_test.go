@@ -0,0 +1,46 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package command allows you to define subcommands
+// for your command line interfaces. It extends the flag package
+// to provide flag support for subcommands.
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+// Tests if map flags collect key=value pairs.
+func TestStringMap(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	labels := StringMap(fs, "label", map[string]string{"team": "infra", "env": "dev"}, "")
+	if err := fs.Parse([]string{"-label", "env=prod", "-label=tier=1"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"team": "infra", "env": "prod", "tier": "1"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("expected labels %v, found %v", want, labels)
+	}
+	if def := fs.Lookup("label").DefValue; def != "env=dev,team=infra" {
+		t.Errorf("unexpected default %q", def)
+	}
+	for _, arg := range []string{"-label=novalue", "-label==x"} {
+		if err := fs.Parse([]string{arg}); err == nil {
+			t.Errorf("%s: an error was expected for a malformed pair", arg)
+		}
+	}
+}
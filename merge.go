@@ -0,0 +1,92 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergeOption configures how MergeFlags defines the flags.
+type MergeOption func(*mergeConfig)
+
+type mergeConfig struct {
+	prefix         string
+	skipDuplicates bool
+}
+
+// MergePrefix prefixes the names of the merged flags, e.g. "log-"
+// for -log-level.
+func MergePrefix(prefix string) MergeOption {
+	return func(c *mergeConfig) {
+		c.prefix = prefix
+	}
+}
+
+// MergeSkipDuplicates keeps the flags already defined on the
+// destination instead of panicking if a merged one has their name.
+func MergeSkipDuplicates() MergeOption {
+	return func(c *mergeConfig) {
+		c.skipDuplicates = true
+	}
+}
+
+// MergeFlags defines the flags of src on dst, e.g. a bundle of
+// logging flags shared by the sub-commands:
+//
+//	var logFlags = flag.NewFlagSet("log", flag.ContinueOnError)
+//	var logLevel = logFlags.String("level", "info", "log level")
+//
+//	func (cmd *ServeCommand) Flags(fs *flag.FlagSet) *flag.FlagSet {
+//		command.MergeFlags(fs, logFlags, command.MergePrefix("log-"))
+//		return fs
+//	}
+//
+// The merged flags share their values with the flags of src. It
+// panics listing the merged flags already defined on dst, unless
+// MergeSkipDuplicates is given.
+func MergeFlags(dst, src *flag.FlagSet, opts ...MergeOption) {
+	var c mergeConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	var duplicates []string
+	src.VisitAll(func(f *flag.Flag) {
+		if name := c.prefix + f.Name; dst.Lookup(name) != nil && !c.skipDuplicates {
+			duplicates = append(duplicates, "-"+name)
+		}
+	})
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		panic(fmt.Sprintf("command: merging flags already defined: %s", strings.Join(duplicates, ", ")))
+	}
+	src.VisitAll(func(f *flag.Flag) {
+		name := c.prefix + f.Name
+		if dst.Lookup(name) != nil {
+			return
+		}
+		v := f.Value
+		switch a := f.Value.(type) {
+		case *aliasValue:
+			v = &aliasValue{fs: dst, name: c.prefix + a.name}
+		case *negatedValue:
+			v = &negatedValue{fs: dst, name: c.prefix + a.name}
+		}
+		dst.Var(v, name, f.Usage)
+		dst.Lookup(name).DefValue = f.DefValue
+	})
+}
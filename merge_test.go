@@ -0,0 +1,55 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+// Tests if the flags of a bundle are merged into flag sets.
+func TestMergeFlags(t *testing.T) {
+	bundle := flag.NewFlagSet("log", flag.ContinueOnError)
+	level := bundle.String("level", "info", "log level")
+	NegatableBool(bundle, "color", true, "colors the output")
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.String("level", "", "compression level")
+	MergeFlags(fs, bundle, MergePrefix("log-"))
+	if err := fs.Parse([]string{"-log-level", "debug", "-log-no-color"}); err != nil {
+		t.Fatal(err)
+	}
+	if *level != "debug" || bundle.Lookup("color").Value.String() != "false" {
+		t.Errorf("expected the bundle values to be set, found %q and %v", *level, bundle.Lookup("color").Value)
+	}
+	if def := fs.Lookup("log-level").DefValue; def != "info" {
+		t.Errorf("expected the default of the bundle, found %q", def)
+	}
+
+	fs = flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.String("level", "", "")
+	MergeFlags(fs, bundle, MergeSkipDuplicates())
+	if fs.Lookup("level").Usage != "" || fs.Lookup("no-color") == nil {
+		t.Error("expected the flags of fs to be kept and the others merged")
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "-level") {
+			t.Errorf("expected a panic listing -level, found %v", r)
+		}
+	}()
+	MergeFlags(fs, bundle)
+}
@@ -0,0 +1,80 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Aliases registers alternative names the sub-command is run by,
+// e.g. "rm" for "remove". They are completed and listed in the usage
+// of the sub-command.
+func Aliases(names ...string) Option {
+	return func(cont *cmdCont) {
+		cont.aliases = append(cont.aliases, names...)
+	}
+}
+
+// Long sets the long description of the sub-command, printed in its
+// usage below the usage line.
+func Long(description string) Option {
+	return func(cont *cmdCont) {
+		cont.long = description
+	}
+}
+
+// Examples adds usage examples of the sub-command, e.g.
+// "program add -v file.txt", printed in its usage.
+func Examples(examples ...string) Option {
+	return func(cont *cmdCont) {
+		cont.examples = append(cont.examples, examples...)
+	}
+}
+
+// SeeAlso adds the names of related sub-commands, printed in the
+// usage of the sub-command.
+func SeeAlso(names ...string) Option {
+	return func(cont *cmdCont) {
+		cont.seeAlso = append(cont.seeAlso, names...)
+	}
+}
+
+// Owner sets the author or owner of the sub-command, printed in its
+// usage.
+func Owner(owner string) Option {
+	return func(cont *cmdCont) {
+		cont.owner = owner
+	}
+}
+
+// Prints the examples, related sub-commands and owner of the
+// sub-command, if it has any.
+func (cont *cmdCont) printMetadata(w io.Writer) {
+	if len(cont.examples) > 0 {
+		fmt.Fprintf(w, "\n%s\n", UsageStrings.Examples)
+		for _, example := range cont.examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+	if len(cont.seeAlso) > 0 {
+		fmt.Fprintf(w, "\n%s\n", UsageStrings.SeeAlso)
+		fmt.Fprintf(w, "  %s\n", strings.Join(cont.seeAlso, ", "))
+	}
+	if cont.owner != "" {
+		fmt.Fprintf(w, "\n"+UsageStrings.Owner+"\n", cont.owner)
+	}
+}
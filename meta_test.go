@@ -0,0 +1,78 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests if the metadata of a sub-command is printed in its usage.
+func TestMetadata(t *testing.T) {
+	resetForTesting()
+
+	On("command1", "", &testCmd1{}, nil,
+		Long("Command1 does things at length."),
+		Examples("cmd command1 -flag1", "cmd command1"),
+		SeeAlso("command2"),
+		Owner("infra"))
+	out, err := SubcommandUsageString("command1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Command1 does things at length.",
+		"examples:\n  cmd command1 -flag1\n  cmd command1\n",
+		"see also:\n  command2\n",
+		"owner: infra\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the usage, found %q", want, out)
+		}
+	}
+	if info, _ := Lookup("command1"); info.Owner != "infra" || len(info.Examples) != 2 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+}
+
+// Tests if aliases run the sub-command and are completed.
+func TestAliases(t *testing.T) {
+	resetForTesting("rm", "-flag1")
+
+	c1 := &testCmd1{}
+	On("remove", "removes", c1, []string{"flag1"}, Aliases("rm", "delete"))
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run || !*c1.flag1 {
+		t.Error("command 'remove' was expected to run by its alias")
+	}
+	if found := defaultSet.complete([]string{"r"}); !reflect.DeepEqual(found, []string{"remove", "rm"}) {
+		t.Errorf("unexpected completions: %v", found)
+	}
+	if usage := UsageString(); strings.Contains(usage, "delete") {
+		t.Errorf("aliases were not expected in the usage, found %q", usage)
+	}
+	if usage, _ := SubcommandUsageString("rm"); !strings.Contains(usage, "aliases: rm, delete") {
+		t.Errorf("expected the aliases in the sub-command usage, found %q", usage)
+	}
+	if infos := Commands(); len(infos) != 1 {
+		t.Errorf("expected a single command, found %v", infos)
+	}
+	if !Off("delete") || len(defaultSet.cmds) != 0 {
+		t.Error("the command and its aliases were expected to be deregistered")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnableMultiCall allows the program to be installed under multiple
+// names, busybox-style. If the program name without prefix matches
+// a registered sub-command, e.g. `myapp-status` with "myapp-" prefix,
+// Parse dispatches to it and all of the arguments are passed to the
+// sub-command. Otherwise, the arguments are parsed as usual.
+func EnableMultiCall(prefix string) {
+	defaultSet.EnableMultiCall(prefix)
+}
+
+// EnableMultiCall allows the program name to select a sub-command
+// of the set.
+func (s *CommandSet) EnableMultiCall(prefix string) {
+	s.multiCallEnabled = true
+	s.multiCallPrefix = prefix
+}
+
+// Returns the sub-command selected by the program name, if any.
+func (s *CommandSet) multiCallCmd() (*cmdCont, bool) {
+	if !s.multiCallEnabled {
+		return nil, false
+	}
+	base := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	if !strings.HasPrefix(base, s.multiCallPrefix) {
+		return nil, false
+	}
+	cont, ok := s.cmds[strings.TrimPrefix(base, s.multiCallPrefix)]
+	return cont, ok
+}
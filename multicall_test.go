@@ -0,0 +1,85 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests if the program name selects the sub-command.
+func TestMultiCall(t *testing.T) {
+	resetForTesting("-flag1", "somearg")
+
+	os.Args[0] = "/usr/bin/myapp-command1"
+	c1 := &testCmd1{}
+	EnableMultiCall("myapp-")
+	On("command1", "", c1, []string{})
+	Parse()
+	Run()
+	if !c1.run || !*c1.flag1 {
+		t.Error("command 'command1' was expected to run with flag1")
+	}
+	if args := parsedArgs(); len(args) != 1 || args[0] != "somearg" {
+		t.Errorf("expected args [somearg], found %v", args)
+	}
+}
+
+// Tests if the plain program name falls back to subcommand parsing.
+func TestMultiCallFallback(t *testing.T) {
+	resetForTesting("command2")
+
+	os.Args[0] = "/usr/bin/myapp"
+	c2 := &testCmd2{}
+	EnableMultiCall("myapp-")
+	On("command1", "", &testCmd1{}, []string{})
+	On("command2", "", c2, []string{})
+	Parse()
+	Run()
+	if !c2.run {
+		t.Error("command 'command2' was expected to run")
+	}
+}
+
+// Tests if the flags of a multi-call sub-command are resolved from a
+// dotenv file.
+func TestMultiCallDotEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(path, []byte("MYAPP_COMMAND1_FLAG1=true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("MYAPP_COMMAND1_FLAG1")
+	resetForTesting()
+
+	os.Args[0] = "/usr/bin/myapp-command1"
+	c1 := &testCmd1{}
+	EnableMultiCall("myapp-")
+	On("command1", "", c1, []string{})
+	SetEnvPrefix("MYAPP")
+	SetDotEnv(path)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if !*c1.flag1 {
+		t.Error("flag1 was expected to be set from the dotenv file")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// OnSet registers sub as a nested set of sub-commands for the
+// provided sub-command name, e.g. "remote" for "tool remote add".
+// The arguments following the name are parsed by sub, its global
+// flags are the flags of the sub-command.
+func OnSet(name, description string, sub *CommandSet, opts ...Option) {
+	defaultSet.OnSet(name, description, sub, opts...)
+}
+
+// OnSet registers sub as a nested set of the set like the
+// package-level OnSet.
+func (s *CommandSet) OnSet(name, description string, sub *CommandSet, opts ...Option) {
+	sub.parent = s
+	sub.cmdName = name
+	s.On(name, description, &nestedCmd{set: sub}, nil, append([]Option{DisableFlagParsing()}, opts...)...)
+}
+
+// Mount registers sub under name like OnSet, without a description,
+// e.g. to compose a CLI of command sets built by other packages.
+// The usage, required flags and completion of sub are re-rooted
+// under the name.
+func Mount(name string, sub *CommandSet, opts ...Option) {
+	defaultSet.Mount(name, sub, opts...)
+}
+
+// Mount registers sub under name in the set.
+func (s *CommandSet) Mount(name string, sub *CommandSet, opts ...Option) {
+	s.OnSet(name, "", sub, opts...)
+}
+
+// nestedCmd is the Cmd registered for a nested set. The arguments
+// are parsed by the set itself, RunE is only called when it's used
+// directly, e.g. as a pipeline stage.
+type nestedCmd struct {
+	set *CommandSet
+}
+
+func (n *nestedCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	return fs
+}
+
+func (n *nestedCmd) Run(args []string) {
+	if err := n.RunE(args); err != nil {
+		fmt.Fprintln(n.set.stderr(), err)
+	}
+}
+
+// RunE parses the arguments with the nested set and runs the matching
+// sub-command, returns the parse error or the error of its run.
+func (n *nestedCmd) RunE(args []string) error {
+	res, err := n.set.parse(args, flag.ContinueOnError)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return nil
+	}
+	return res.runErr(context.Background())
+}
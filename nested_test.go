@@ -0,0 +1,130 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests if nested sub-commands run with the flags of every level.
+func TestOnSet(t *testing.T) {
+	resetForTesting("remote", "-v", "add", "-flag1", "origin")
+
+	remote := New("remote")
+	verbose := remote.Flags().Bool("v", false, "")
+	c1 := &testCmd1{}
+	remote.On("add", "", c1, []string{"flag1"})
+	OnSet("remote", "manages remotes", remote)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunErr(); err != nil {
+		t.Fatal(err)
+	}
+	if !*verbose || !c1.run || !*c1.flag1 {
+		t.Error("command 'remote add' was expected to run with -v and -flag1")
+	}
+	if args := parsedArgs(); !reflect.DeepEqual(args, []string{"origin"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	resetForTesting("remote", "add")
+	remote = New("remote")
+	remote.On("add", "", &testCmd1{}, []string{"flag1"})
+	OnSet("remote", "", remote)
+	if _, ok := ParseErr().(Errors); !ok {
+		t.Error("an error was expected for the required flag of 'remote add'")
+	}
+}
+
+// Tests if an unknown nested sub-command prints the usage of its set.
+func TestOnSetUnknown(t *testing.T) {
+	resetForTesting("remote", "ad")
+	defer func(f func(int)) { exit = f }(exit)
+	exit = func(int) {}
+
+	remote := New("remote")
+	remote.On("add", "adds a remote", &testCmd1{}, nil)
+	OnSet("remote", "", remote)
+	out := captureStderr(func() { Parse() })
+	prog := os.Args[0] + " remote"
+	if !strings.Contains(out, `unknown command "ad" for "`+prog+`"`) {
+		t.Errorf("expected the unknown command with its path, found %q", out)
+	}
+	if !strings.Contains(out, "Usage: "+prog+" <command>") || !strings.Contains(out, "adds a remote") {
+		t.Errorf("expected the usage of the nested set, found %q", out)
+	}
+}
+
+// Tests if an unknown sub-command two levels deep is reported with
+// suggestions and its path.
+func TestOnSetUnknownTwoLevels(t *testing.T) {
+	resetForTesting("remote", "branch", "lsit")
+	defer func(f func(int)) { exit = f }(exit)
+	exit = func(int) {}
+
+	branch := New("branch")
+	branch.On("list", "lists the branches", &testCmd1{}, nil)
+	remote := New("remote")
+	remote.OnSet("branch", "", branch)
+	OnSet("remote", "", remote)
+	if _, ok := ParseErr().(*UnknownCommandError); !ok {
+		t.Error("an unknown command error was expected for 'remote branch lsit'")
+	}
+	out := captureStderr(func() { Parse() })
+	prog := os.Args[0] + " remote branch"
+	if !strings.Contains(out, `unknown command "lsit" for "`+prog+`"`) {
+		t.Errorf("expected the unknown command with its path, found %q", out)
+	}
+	if !strings.Contains(out, "Did you mean this?\n\tlist\n") {
+		t.Errorf("list was expected to be suggested, found %q", out)
+	}
+}
+
+// Tests if nested sub-commands and their flags are completed.
+func TestOnSetComplete(t *testing.T) {
+	resetForTesting()
+
+	remote := New("remote")
+	remote.On("add", "", &testCmd1{}, nil)
+	remote.On("remove", "", &testCmd1{}, nil)
+	OnSet("remote", "", remote)
+	On("status", "", &testCmd2{}, nil)
+	if got := defaultSet.complete([]string{"remote", "re"}); !reflect.DeepEqual(got, []string{"remove"}) {
+		t.Errorf("unexpected completions: %v", got)
+	}
+	if got := defaultSet.complete([]string{"remote", "add", "-fl"}); !reflect.DeepEqual(got, []string{"-flag1"}) {
+		t.Errorf("unexpected completions: %v", got)
+	}
+}
+
+// Tests if the usage of a mounted set is re-rooted under its name.
+func TestMount(t *testing.T) {
+	resetForTesting()
+
+	db := New("dbtool")
+	db.On("migrate", "runs migrations", &testCmd1{}, nil)
+	Mount("db", db)
+	out, err := SubcommandUsageString("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Usage: "+os.Args[0]+" db <command>") {
+		t.Errorf("expected the usage of the mounted set, found %q", out)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strings"
+)
+
+// SetNormalizeFunc sets a function normalizing flag names, so the
+// flags given with a name that normalizes to the one of a defined
+// flag resolve to it, e.g. -dry_run and -dryRun to -dry-run with
+// NormalizeSeparators. Defined names are preferred if they match
+// exactly.
+func SetNormalizeFunc(fn func(name string) string) {
+	defaultSet.SetNormalizeFunc(fn)
+}
+
+// SetNormalizeFunc sets a function normalizing the flag names of
+// the set and its sub-commands.
+func (s *CommandSet) SetNormalizeFunc(fn func(name string) string) {
+	s.normalize = fn
+}
+
+// NormalizeSeparators normalizes a flag name to lower case without
+// dashes and underscores, so dry-run, dry_run and dryRun are the same.
+func NormalizeSeparators(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
+
+// Returns the arguments with the flags renamed to the flags of fs
+// their names normalize to. Positionals are only scanned if
+// interspersed.
+func (s *CommandSet) normalizeFlags(fs *flag.FlagSet, arguments []string, interspersed bool) []string {
+	if s.normalize == nil {
+		return arguments
+	}
+	names := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		names[s.normalize(f.Name)] = f.Name
+	})
+	normalized := make([]string, 0, len(arguments))
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			if arg == "--" || !interspersed {
+				return append(normalized, arguments[i:]...)
+			}
+			normalized = append(normalized, arg)
+			continue
+		}
+		dashes := "-"
+		if arg[1] == '-' {
+			dashes = "--"
+		}
+		name, value := arg[len(dashes):], ""
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value = name[:j], name[j:]
+		}
+		if fs.Lookup(name) == nil {
+			if defined, ok := names[s.normalize(name)]; ok {
+				name = defined
+			}
+		}
+		normalized = append(normalized, dashes+name+value)
+		if f := fs.Lookup(name); f != nil && value == "" && !isBoolFlag(f) && i+1 < len(arguments) {
+			// skip the value.
+			i++
+			normalized = append(normalized, arguments[i])
+		}
+	}
+	return normalized
+}
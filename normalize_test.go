@@ -0,0 +1,73 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+// Tests if flag names are normalized to the defined ones.
+func TestNormalizeFlags(t *testing.T) {
+	s := New("test")
+	s.SetNormalizeFunc(NormalizeSeparators)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("dry-run", false, "")
+	fs.String("output-dir", "", "")
+	fs.String("dry_run_x", "", "")
+	for _, tt := range []struct {
+		args         []string
+		interspersed bool
+		want         []string
+	}{
+		{[]string{"--dry_run", "-dryRun=true"}, false, []string{"--dry-run", "-dry-run=true"}},
+		{[]string{"-outputDir", "-dry_run", "a"}, false, []string{"-output-dir", "-dry_run", "a"}},
+		{[]string{"-dry_run_x", "v", "a", "-dryRun"}, false, []string{"-dry_run_x", "v", "a", "-dryRun"}},
+		{[]string{"a", "-DryRun", "--", "-dryRun"}, true, []string{"a", "-dry-run", "--", "-dryRun"}},
+	} {
+		if found := s.normalizeFlags(fs, tt.args, tt.interspersed); !reflect.DeepEqual(found, tt.want) {
+			t.Errorf("%v: expected %v, found %v", tt.args, tt.want, found)
+		}
+	}
+}
+
+// Tests if normalized flags of a sub-command are parsed.
+func TestNormalizedSubcommandFlags(t *testing.T) {
+	resetForTesting("command1", "-dryRun")
+
+	var dryRun *bool
+	On("command1", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		dryRun = fs.Bool("dry-run", false, "")
+	}}, nil)
+	SetNormalizeFunc(NormalizeSeparators)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if !*dryRun {
+		t.Error("-dry-run was not set")
+	}
+}
+
+// flagsFuncCmd is a test sub command defining its flags with a func.
+type flagsFuncCmd struct {
+	testCmd1
+	flags func(fs *flag.FlagSet)
+}
+
+func (cmd *flagsFuncCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	cmd.flags(fs)
+	return fs
+}
@@ -0,0 +1,45 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+// Passthrough returns the arguments following the "--" terminating
+// the flags of the matched sub-command, as provided even if they look
+// like flags, e.g. ["-v", "x"] for `program exec -- -v x`. It returns
+// nil if there is no terminator. They are also the trailing arguments
+// passed to the runnable, it's useful for wrappers running other
+// programs.
+func Passthrough() []string {
+	return defaultSet.Passthrough()
+}
+
+// Passthrough returns the arguments following the "--" terminating
+// the flags of the sub-command matched by the set.
+func (s *CommandSet) Passthrough() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matching == nil {
+		return nil
+	}
+	return s.matching.passthrough
+}
+
+// Returns the arguments following the "--" if parsing the arguments
+// left rest after it, nil otherwise.
+func terminated(arguments, rest []string) []string {
+	if n := len(arguments) - len(rest); n > 0 && arguments[n-1] == "--" {
+		return append([]string{}, rest...)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests if the arguments following a terminator are exposed.
+func TestPassthrough(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		opts []Option
+		want []string
+	}{
+		{[]string{"command1", "-flag1", "a", "--", "-b"}, nil, nil},
+		{[]string{"command1", "-flag1", "--", "a", "-b"}, nil, []string{"a", "-b"}},
+		{[]string{"command1", "--"}, nil, []string{}},
+		{[]string{"command1", "--", "-flag1"}, []Option{DisableFlagParsing()}, []string{"-flag1"}},
+	} {
+		resetForTesting(tt.args...)
+		On("command1", "", &testCmd1{}, nil, tt.opts...)
+		if err := ParseErr(); err != nil {
+			t.Fatal(err)
+		}
+		if found := Passthrough(); !reflect.DeepEqual(found, tt.want) {
+			t.Errorf("%v: expected %#v, found %#v", tt.args, tt.want, found)
+		}
+	}
+}
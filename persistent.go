@@ -0,0 +1,114 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"reflect"
+)
+
+// PersistentFlags returns the flag set whose flags are inherited by
+// every sub-command of the set and of its nested sets, e.g. -verbose.
+// They are parsed along with the global flags and the flags of the
+// sub-commands, and are listed in their usage. Define them before
+// calling Parse.
+func PersistentFlags() *flag.FlagSet {
+	return defaultSet.PersistentFlags()
+}
+
+// PersistentFlags returns the persistent flag set of the set.
+func (s *CommandSet) PersistentFlags() *flag.FlagSet {
+	if s.persistent == nil {
+		s.persistent = flag.NewFlagSet(s.program(), flag.ContinueOnError)
+	}
+	return s.persistent
+}
+
+// Defines the persistent flags of the set and of the sets it's nested
+// in on fs, unless fs defines them.
+func (s *CommandSet) definePersistentFlags(fs *flag.FlagSet) {
+	for set := s; set != nil; set = set.parent {
+		if set.persistent == nil {
+			continue
+		}
+		set.persistent.VisitAll(func(f *flag.Flag) {
+			if fs.Lookup(f.Name) == nil {
+				fs.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+	}
+}
+
+// Reports whether v is the value of the persistent flag name of the
+// set or of the sets it's nested in.
+func (s *CommandSet) isPersistentValue(name string, v flag.Value) bool {
+	if !reflect.TypeOf(v).Comparable() {
+		return false
+	}
+	for set := s; set != nil; set = set.parent {
+		if set.persistent == nil {
+			continue
+		}
+		if f := set.persistent.Lookup(name); f != nil && f.Value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the names of the persistent flags provided before the
+// sub-command name, to the global flags of the set or of the sets
+// it's nested in.
+func (s *CommandSet) providedPersistentFlags(globals *flag.FlagSet) map[string]bool {
+	provided := make(map[string]bool)
+	if s.parent != nil {
+		for name := range s.parent.persistentProvided {
+			provided[name] = true
+		}
+	}
+	for name := range setFlags(globals) {
+		if f := globals.Lookup(name); f != nil && s.isPersistentValue(name, f.Value) {
+			provided[name] = true
+		}
+	}
+	return provided
+}
+
+// Marks the persistent flags provided before the sub-command name as
+// set on fs, so they take precedence over the environment, config
+// files and profiles like the flags provided after it. Their values
+// aren't set again.
+func (s *CommandSet) markPersistentFlags(fs *flag.FlagSet) {
+	set := setFlags(fs)
+	for name := range s.persistentProvided {
+		f := fs.Lookup(name)
+		if f == nil || set[name] || !s.isPersistentValue(name, f.Value) {
+			continue
+		}
+		v := f.Value
+		f.Value = keptValue{valueWrapper{v}}
+		fs.Set(name, v.String())
+		f.Value = v
+	}
+}
+
+// keptValue ignores the values set to the value it wraps.
+type keptValue struct {
+	valueWrapper
+}
+
+func (keptValue) Set(string) error {
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Tests if persistent flags are parsed before and after the names of
+// nested sub-commands.
+func TestPersistentFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"-verbose", "remote", "add"},
+		{"remote", "-verbose", "add"},
+		{"remote", "add", "-verbose"},
+	} {
+		resetForTesting(args...)
+		verbose := PersistentFlags().Bool("verbose", false, "prints more")
+		remote := New("remote")
+		remote.On("add", "", &testCmd1{}, nil)
+		OnSet("remote", "", remote)
+		if err := ParseErr(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		if !*verbose {
+			t.Errorf("%v: -verbose was expected to be set", args)
+		}
+	}
+
+	resetForTesting()
+	PersistentFlags().Bool("verbose", false, "prints more")
+	On("command1", "", &testCmd1{}, nil)
+	if usage, _ := SubcommandUsageString("command1"); !strings.Contains(usage, "prints more") {
+		t.Errorf("expected the persistent flag in the usage, found %q", usage)
+	}
+}
+
+// Tests if persistent flags provided before the sub-command name take
+// precedence over the environment and config files.
+func TestPersistentFlagsPrecedence(t *testing.T) {
+	path := writeConfigForTesting(t, `{"verbose": true}`)
+	defer os.Remove(path)
+	os.Setenv("T_COMMAND1_VERBOSE", "true")
+	defer os.Unsetenv("T_COMMAND1_VERBOSE")
+	resetForTesting("-config", path, "-verbose=false", "command1")
+
+	verbose := PersistentFlags().Bool("verbose", false, "prints more")
+	EnableConfigFlag()
+	SetEnvPrefix("T")
+	On("command1", "", &testCmd1{}, nil)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if *verbose {
+		t.Error("-verbose=false was expected to override the environment and the config file")
+	}
+	if source := FlagSource("verbose"); source != "flag" {
+		t.Errorf("expected -verbose from the command line, found %q", source)
+	}
+}
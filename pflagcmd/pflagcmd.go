@@ -0,0 +1,107 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pflagcmd registers sub-commands whose flags are parsed
+// with github.com/spf13/pflag, providing GNU-style --long and -s
+// flags, while the other sub-commands stay on the flag package.
+package pflagcmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rakyll/command"
+	"github.com/spf13/pflag"
+)
+
+// Cmd is a sub-command whose flags are defined on a pflag.FlagSet.
+type Cmd interface {
+	Flags(*pflag.FlagSet) *pflag.FlagSet
+	Run(args []string)
+}
+
+// On registers c for the provided sub-command name like command.On.
+// Its arguments are parsed with pflag, and the required flags are
+// checked once they are parsed.
+func On(name, description string, c Cmd, requiredFlags []string, opts ...command.Option) {
+	command.On(name, description, Wrap(name, c, requiredFlags), nil, append(opts, command.DisableFlagParsing())...)
+}
+
+// Wrap returns a command.Cmd parsing its arguments with pflag before
+// running c. Register it with command.DisableFlagParsing so the
+// arguments are passed as they are. Parse errors and missing required
+// flags are returned as a *command.UsageError.
+func Wrap(name string, c Cmd, requiredFlags []string) command.CmdE {
+	return &cmd{name: name, c: c, requiredFlags: requiredFlags, stderr: os.Stderr}
+}
+
+type cmd struct {
+	name          string
+	c             Cmd
+	requiredFlags []string
+	// the stream the usage and errors are written to.
+	stderr io.Writer
+}
+
+// SetIO sets the stream the usage and errors are written to, the
+// stderr of the set the sub-command is registered in.
+func (w *cmd) SetIO(in io.Reader, out, err io.Writer) {
+	w.stderr = err
+}
+
+func (w *cmd) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(w.name, pflag.ContinueOnError)
+	fs.SetOutput(w.stderr)
+	return w.c.Flags(fs)
+}
+
+// Flags defines the pflag flags on fs, so they are listed in the
+// usage of the sub-command.
+func (w *cmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	w.flagSet().VisitAll(func(f *pflag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	return fs
+}
+
+func (w *cmd) Run(args []string) {
+	if err := w.RunE(args); err != nil {
+		fmt.Fprintln(w.stderr, err)
+	}
+}
+
+func (w *cmd) RunE(args []string) error {
+	fs := w.flagSet()
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return nil
+		}
+		fmt.Fprintf(w.stderr, "Usage of %s:\n%s", w.name, fs.FlagUsages())
+		return &command.UsageError{Err: err}
+	}
+	var missing []string
+	for _, name := range w.requiredFlags {
+		if !fs.Changed(name) {
+			missing = append(missing, "--"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return &command.UsageError{Err: fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))}
+	}
+	w.c.Run(fs.Args())
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pflagcmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rakyll/command"
+	"github.com/spf13/pflag"
+)
+
+type testCmd struct {
+	name    *string
+	verbose *bool
+
+	args []string
+}
+
+func (c *testCmd) Flags(fs *pflag.FlagSet) *pflag.FlagSet {
+	c.name = fs.String("name", "", "")
+	c.verbose = fs.BoolP("verbose", "v", false, "")
+	return fs
+}
+
+func (c *testCmd) Run(args []string) {
+	c.args = args
+}
+
+// Tests if GNU-style flags are parsed.
+func TestWrap(t *testing.T) {
+	c := &testCmd{}
+	if err := Wrap("test", c, []string{"name"}).RunE([]string{"--name", "x", "arg", "-v"}); err != nil {
+		t.Fatal(err)
+	}
+	if *c.name != "x" || !*c.verbose || !reflect.DeepEqual(c.args, []string{"arg"}) {
+		t.Errorf("unexpected flags or args: %q %v %v", *c.name, *c.verbose, c.args)
+	}
+	if err := Wrap("test", &testCmd{}, []string{"name"}).RunE([]string{"-v"}); err == nil {
+		t.Error("an error was expected for the missing --name")
+	}
+}
+
+// Tests if parse errors are written to the stderr of the set and
+// returned as usage errors.
+func TestWrapUsageError(t *testing.T) {
+	var stderr bytes.Buffer
+	set := command.New("tool")
+	set.SetIO(nil, nil, &stderr)
+	set.On("test", "", Wrap("test", &testCmd{}, nil), nil, command.DisableFlagParsing())
+	err := set.ParseAndRunE([]string{"test", "--unknown"})
+	if e, ok := err.(*command.UsageError); !ok || e.ExitCode() != 2 {
+		t.Errorf("expected a usage error, found %v", err)
+	}
+	if !strings.Contains(stderr.String(), "--name") {
+		t.Errorf("expected the usage on the stderr of the set, found %q", stderr.String())
+	}
+}
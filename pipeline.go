@@ -0,0 +1,85 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"flag"
+)
+
+// SetPipelineSeparator sets the token RunPipeline splits the
+// arguments with, e.g. ":::" for `a -x ::: b -y`.
+func SetPipelineSeparator(token string) {
+	defaultSet.SetPipelineSeparator(token)
+}
+
+// SetPipelineSeparator sets the token RunPipeline of the set
+// splits the arguments with.
+func (s *CommandSet) SetPipelineSeparator(token string) {
+	s.pipelineSeparator = token
+}
+
+// RunPipeline splits the arguments with the pipeline separator and
+// runs each stage as a sub-command invocation in order. Each stage
+// is parsed right before it runs, the pipeline is aborted with an
+// error if a stage doesn't match a registered sub-command, its
+// arguments can't be parsed or its runnable fails.
+func RunPipeline(arguments []string) error {
+	return defaultSet.RunPipeline(arguments)
+}
+
+// RunPipeline runs each stage of the pipeline as a sub-command
+// invocation of the set in order.
+func (s *CommandSet) RunPipeline(arguments []string) error {
+	for _, stage := range s.splitPipeline(arguments) {
+		if len(stage) == 0 {
+			return errors.New("command: empty pipeline stage")
+		}
+		s.mu.Lock()
+		cont, ok := s.cmds[stage[0]]
+		if !ok {
+			s.mu.Unlock()
+			return &UnknownCommandError{Name: stage[0]}
+		}
+		res, err := s.parseCmd(cont, stage[1:], flag.ContinueOnError)
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		if err := res.runErr(context.Background()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Splits the arguments into the stages of a pipeline.
+func (s *CommandSet) splitPipeline(arguments []string) [][]string {
+	if s.pipelineSeparator == "" {
+		return [][]string{arguments}
+	}
+	var stages [][]string
+	stage := []string{}
+	for _, arg := range arguments {
+		if arg == s.pipelineSeparator {
+			stages = append(stages, stage)
+			stage = []string{}
+			continue
+		}
+		stage = append(stage, arg)
+	}
+	return append(stages, stage)
+}
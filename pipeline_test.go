@@ -0,0 +1,121 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"testing"
+)
+
+// Tests if each stage of a pipeline runs with its own flags.
+func TestRunPipeline(t *testing.T) {
+	resetForTesting()
+
+	c1 := &testCmd1{}
+	c2 := &testCmd2{}
+	On("command1", "", c1, []string{})
+	On("command2", "", c2, []string{})
+	SetPipelineSeparator(":::")
+	if err := RunPipeline([]string{"command1", "-flag1", ":::", "command2", "-flag2"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run || !*c1.flag1 {
+		t.Error("command 'command1' was expected to run with flag1")
+	}
+	if !c2.run || !*c2.flag2 {
+		t.Error("command 'command2' was expected to run with flag2")
+	}
+}
+
+// Tests if a pipeline is aborted at the first failing stage.
+func TestRunPipelineError(t *testing.T) {
+	resetForTesting()
+
+	c1 := &testCmd1{}
+	c2 := &testCmd2{}
+	On("command1", "", c1, []string{"flag1"})
+	On("command2", "", c2, []string{})
+	SetPipelineSeparator(":::")
+	if err := RunPipeline([]string{"command2", ":::", "command1", ":::", "command2"}); err == nil {
+		t.Fatal("an error was expected for the missing required flag")
+	}
+	if !c2.run {
+		t.Error("command 'command2' was expected to run before the failing stage")
+	}
+	if c1.run {
+		t.Error("command 'command1' was not expected to run")
+	}
+}
+
+// Tests if a pipeline is aborted at the first stage failing to run.
+func TestRunPipelineRunError(t *testing.T) {
+	resetForTesting()
+
+	c1 := &errCmd{err: errors.New("failed")}
+	c2 := &testCmd2{}
+	On("command1", "", c1, []string{})
+	On("command2", "", c2, []string{})
+	SetPipelineSeparator(":::")
+	if err := RunPipeline([]string{"command1", ":::", "command2"}); err == nil || err.Error() != "failed" {
+		t.Fatalf("expected the error of command1, found %v", err)
+	}
+	if c2.run {
+		t.Error("command 'command2' was not expected to run")
+	}
+}
+
+// Tests if pipeline stages requiring a confirmation only run once
+// it's given.
+func TestRunPipelineConfirmation(t *testing.T) {
+	resetForTesting()
+	defer setStdinForTesting(nil, false)()
+
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{}, RequireConfirmation("continue?"))
+	SetPipelineSeparator(":::")
+	if err := RunPipeline([]string{"command1"}); err == nil {
+		t.Error("an error was expected without a confirmation")
+	}
+	if c1.run {
+		t.Error("command 'command1' was not expected to run without a confirmation")
+	}
+	if err := RunPipeline([]string{"command1", "-yes"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c1.run {
+		t.Error("command 'command1' was expected to run with -yes")
+	}
+}
+
+// Tests if a pipeline is aborted at a failing nested stage.
+func TestRunPipelineNestedError(t *testing.T) {
+	resetForTesting()
+
+	remote := New("remote")
+	remote.On("fail", "", &errCmd{err: errors.New("failed")}, []string{})
+	OnSet("remote", "", remote)
+	c1 := &testCmd1{}
+	On("command1", "", c1, []string{})
+	SetPipelineSeparator(":::")
+	if _, ok := RunPipeline([]string{"remote", "bogus", ":::", "command1"}).(*UnknownCommandError); !ok {
+		t.Error("an unknown command error was expected for 'remote bogus'")
+	}
+	if err := RunPipeline([]string{"remote", "fail", ":::", "command1"}); err == nil || err.Error() != "failed" {
+		t.Errorf("expected the error of 'remote fail', found %v", err)
+	}
+	if c1.run {
+		t.Error("command 'command1' was not expected to run")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Name of the flag selecting a profile, and of the config file table
+// defining the profiles.
+const (
+	profileFlagName = "profile"
+	profilesKey     = "profiles"
+)
+
+// EnableProfiles registers a -profile flag on every sub-command and
+// as a global flag. It selects a named preset of flag values, e.g.
+// -profile prod, applied to the flags not set on the command line.
+// Profiles take precedence over the environment and config files.
+// They are defined by DefineProfile or in the profiles table of the
+// config file, which has the layout of the config file:
+//
+//	{"profiles": {"prod": {"verbose": false, "serve": {"port": 443}}}}
+//
+// The values of the config file take precedence over the ones
+// defined by DefineProfile. Values of flags a sub-command doesn't
+// define are ignored.
+func EnableProfiles() {
+	defaultSet.EnableProfiles()
+}
+
+// EnableProfiles registers a -profile flag on every sub-command of
+// the set.
+func (s *CommandSet) EnableProfiles() {
+	s.profilesEnabled = true
+}
+
+// DefineProfile defines the named profile and enables the profiles.
+// The keys of values are flag names, applying to every sub-command
+// defining them, or a sub-command name and one of its flags separated
+// by a dot, e.g. "serve.port".
+func DefineProfile(name string, values map[string]string) {
+	defaultSet.DefineProfile(name, values)
+}
+
+// DefineProfile defines the named profile of the set.
+func (s *CommandSet) DefineProfile(name string, values map[string]string) {
+	s.profilesEnabled = true
+	if s.profiles == nil {
+		s.profiles = make(map[string]map[string]string)
+	}
+	s.profiles[name] = values
+}
+
+// Profile returns the name of the profile selected by the last Parse,
+// or an empty string if none is selected.
+func Profile() string {
+	return defaultSet.Profile()
+}
+
+// Profile returns the name of the profile selected by the last Parse
+// of the set.
+func (s *CommandSet) Profile() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.profile
+}
+
+// Defines the profile flag on fs, unless it's already defined.
+func (s *CommandSet) defineProfileFlag(fs *flag.FlagSet) {
+	if s.profilesEnabled && fs.Lookup(profileFlagName) == nil {
+		fs.String(profileFlagName, "", "name of a profile providing flag values")
+	}
+}
+
+// Returns the name of the profile, given to the sub-command or as a
+// global flag.
+func (s *CommandSet) profileName(fs *flag.FlagSet) string {
+	for _, set := range []*flag.FlagSet{fs, s.Flags()} {
+		if f := set.Lookup(profileFlagName); f != nil && f.Value.String() != "" {
+			return f.Value.String()
+		}
+	}
+	return ""
+}
+
+// Sets the values of the selected profile to the flags of fs of the
+// named sub-command which are not set yet, returns the source of the
+// values.
+func (s *CommandSet) applyProfile(fs *flag.FlagSet, cmd string) (source string, err error) {
+	if !s.profilesEnabled {
+		return "", nil
+	}
+	name := s.profileName(fs)
+	s.profile = name
+	if name == "" {
+		return "", nil
+	}
+	values, err := s.profileValues(fs, name, cmd)
+	if err != nil {
+		return "", err
+	}
+	set := setFlags(fs)
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, flagName := range names {
+		if set[flagName] || fs.Lookup(flagName) == nil || flagName == profileFlagName {
+			continue
+		}
+		for _, v := range values[flagName] {
+			if err := fs.Set(flagName, v); err != nil {
+				return "", fmt.Errorf("command: invalid value %q for flag -%s in profile %q: %v", v, flagName, name, err)
+			}
+		}
+	}
+	return "profile:" + name, nil
+}
+
+// Returns the flag values of the named profile for the named
+// sub-command, an error if the profile is unknown.
+func (s *CommandSet) profileValues(fs *flag.FlagSet, name, cmd string) (map[string][]string, error) {
+	defined, ok := s.profiles[name]
+	raw := make(map[string]interface{})
+	for key, v := range defined {
+		if i := strings.Index(key, "."); i >= 0 {
+			table, _ := raw[key[:i]].(map[string]interface{})
+			if table == nil {
+				table = make(map[string]interface{})
+				raw[key[:i]] = table
+			}
+			table[key[i+1:]] = v
+			continue
+		}
+		raw[key] = v
+	}
+	values := s.configValues(raw, cmd)
+	if path := s.configPath(fs); s.configFlagEnabled && path != "" {
+		file, err := loadConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		profiles, _ := file[profilesKey].(map[string]interface{})
+		if profile, found := profiles[name].(map[string]interface{}); found {
+			ok = true
+			for flagName, v := range s.configValues(profile, cmd) {
+				values[flagName] = v
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("command: unknown profile %q", name)
+	}
+	return values, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests if the selected profile provides the flags which are not set
+// on the command line.
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"name": "from-config", "profiles": {"prod": {"serve": {"port": 8443}}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TOOL_SERVE_NAME", "from-env")
+	defer os.Unsetenv("TOOL_SERVE_NAME")
+
+	cmd := &configCmd{}
+	s := NewWithErrorHandling("tool", flag.ContinueOnError)
+	s.SetIO(nil, ioutil.Discard, ioutil.Discard)
+	s.On("serve", "", cmd, nil)
+	s.SetEnvPrefix("TOOL")
+	s.EnableConfigFlag()
+	s.DefineProfile("prod", map[string]string{"port": "443", "serve.name": "prod", "flag1": "true"})
+	if err := s.ParseErr([]string{"-config", path, "-profile", "prod", "serve", "-flag1=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if *cmd.port != 8443 || *cmd.name != "prod" || *cmd.flag1 {
+		t.Errorf("unexpected values %d, %q and %v", *cmd.port, *cmd.name, *cmd.flag1)
+	}
+	if source := s.FlagSource("name"); source != "profile:prod" {
+		t.Errorf("expected the profile source, found %q", source)
+	}
+	if profile := s.Profile(); profile != "prod" {
+		t.Errorf("expected the prod profile, found %q", profile)
+	}
+	if err := s.ParseErr([]string{"serve", "-profile", "dev"}); err == nil || !strings.Contains(err.Error(), `unknown profile "dev"`) {
+		t.Errorf("expected an unknown profile error, found %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// SetInteractiveMode sets whether the required flags missing from the
+// command line, the environment and the config file are prompted for
+// if stdin is a terminal, instead of failing the parsing. The values
+// of flags implementing Secret are read without echoing. A flag left
+// empty is reported missing.
+func SetInteractiveMode(interactive bool) {
+	defaultSet.SetInteractiveMode(interactive)
+}
+
+// SetInteractiveMode sets whether the missing required flags of the
+// sub-commands of the set are prompted for.
+func (s *CommandSet) SetInteractiveMode(interactive bool) {
+	s.interactive = interactive
+}
+
+// Reads a line from r without echoing it.
+var readPassword = func(r io.Reader) (string, error) {
+	f, ok := r.(*os.File)
+	if !ok {
+		return "", errors.New("command: secrets can only be read from a terminal")
+	}
+	b, err := term.ReadPassword(int(f.Fd()))
+	return string(b), err
+}
+
+// Prompts for the values of the required flags of fs which are not
+// set, until a valid value or an empty line is entered.
+func (s *CommandSet) promptRequiredFlags(cont *cmdCont, fs *flag.FlagSet) error {
+	in, _, errOut := s.streams(cont)
+	if !s.interactive || !isTerminal(in) {
+		return nil
+	}
+	set := setFlags(fs)
+	r := bufio.NewReader(in)
+	for _, name := range requiredFlags(cont, fs) {
+		f := fs.Lookup(name)
+		if f == nil || set[name] {
+			continue
+		}
+		secret := wrapsValue(f.Value, func(v flag.Value) bool {
+			secret, ok := v.(Secret)
+			return ok && secret.IsSecret()
+		})
+		prompt := name
+		if usage := strings.TrimSpace(f.Usage); usage != "" {
+			prompt += " (" + usage + ")"
+		}
+		for {
+			fmt.Fprintf(errOut, "%s: ", prompt)
+			var value string
+			var err error
+			if secret {
+				value, err = readPassword(in)
+				fmt.Fprintln(errOut)
+			} else {
+				value, err = r.ReadString('\n')
+			}
+			if err != nil && err != io.EOF {
+				return err
+			}
+			value = strings.TrimRight(value, "\r\n")
+			if value == "" {
+				break
+			}
+			if err := fs.Set(name, value); err != nil {
+				fmt.Fprintf(errOut, "invalid value %q for flag -%s: %v\n", value, name, err)
+				continue
+			}
+			break
+		}
+	}
+	return nil
+}
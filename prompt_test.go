@@ -0,0 +1,90 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// Tests if the missing required flags are prompted for.
+func TestInteractiveMode(t *testing.T) {
+	defer setStdinForTesting(nil, true)()
+	defer func(orig func(io.Reader) (string, error)) { readPassword = orig }(readPassword)
+	readPassword = func(io.Reader) (string, error) { return "s3cr3t", nil }
+
+	var port *int
+	token := &secretValue{}
+	s := NewWithErrorHandling("tool", flag.ContinueOnError)
+	var stderr bytes.Buffer
+	s.SetIO(strings.NewReader("http\n8080\n"), ioutil.Discard, &stderr)
+	s.On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		port = fs.Int("port", 0, "listen port")
+		fs.Var(token, "token", "")
+		fs.String("name", "", "")
+	}}, []string{"port", "token"})
+	s.SetInteractiveMode(true)
+	if err := s.ParseErr([]string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 8080 || token.value != "s3cr3t" {
+		t.Errorf("expected the prompted values, found %d and %q", *port, token.value)
+	}
+	if source := s.FlagSource("port"); source != "prompt" {
+		t.Errorf("expected the prompt source, found %q", source)
+	}
+	if want := "port (listen port): invalid value \"http\""; !strings.Contains(stderr.String(), want) {
+		t.Errorf("expected %q in %q", want, stderr.String())
+	}
+}
+
+// Tests if the missing required flags are not prompted for if stdin
+// is not a terminal.
+func TestInteractiveModeNoTerminal(t *testing.T) {
+	defer setStdinForTesting(nil, false)()
+
+	s := NewWithErrorHandling("tool", flag.ContinueOnError)
+	s.SetIO(strings.NewReader("8080\n"), ioutil.Discard, ioutil.Discard)
+	s.On("serve", "", &configCmd{}, []string{"port"})
+	s.SetInteractiveMode(true)
+	if _, ok := s.ParseErr([]string{"serve"}).(Errors); !ok {
+		t.Error("expected the missing flag to be reported")
+	}
+}
+
+// Tests if the missing required flags are prompted for on the streams
+// of the sub-command.
+func TestInteractiveModeStreams(t *testing.T) {
+	defer setStdinForTesting(nil, true)()
+
+	var port *int
+	var stderr bytes.Buffer
+	s := NewWithErrorHandling("tool", flag.ContinueOnError)
+	s.SetIO(strings.NewReader("1\n"), ioutil.Discard, ioutil.Discard)
+	s.On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		port = fs.Int("port", 0, "")
+	}}, []string{"port"}, Streams(strings.NewReader("8080\n"), nil, &stderr))
+	s.SetInteractiveMode(true)
+	if err := s.ParseErr([]string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 8080 || stderr.String() != "port: " {
+		t.Errorf("expected the value prompted on the streams of serve, found %d and %q", *port, stderr.String())
+	}
+}
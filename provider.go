@@ -0,0 +1,110 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ValueProvider provides the values of flags from a configuration
+// source, e.g. a remote key/value store. cmdPath is the path of the
+// sub-command from the top-level set, e.g. "remote add", or empty for
+// the global flags.
+type ValueProvider interface {
+	Lookup(cmdPath, flagName string) (string, bool)
+}
+
+// ValueProviderFunc adapts a function to a ValueProvider.
+type ValueProviderFunc func(cmdPath, flagName string) (string, bool)
+
+// Lookup calls f.
+func (f ValueProviderFunc) Lookup(cmdPath, flagName string) (string, bool) {
+	return f(cmdPath, flagName)
+}
+
+type namedProvider struct {
+	name string
+	ValueProvider
+}
+
+// AddValueProvider adds a provider of the values of the flags which
+// are set neither on the command line, by the environment nor by
+// the config file. The providers are consulted in the order they are
+// added, the first one providing a value wins. The name is reported
+// as the source of the values, see FlagSource.
+func AddValueProvider(name string, p ValueProvider) {
+	defaultSet.AddValueProvider(name, p)
+}
+
+// AddValueProvider adds a provider of flag values to the set and
+// the sets nested in it.
+func (s *CommandSet) AddValueProvider(name string, p ValueProvider) {
+	s.providers = append(s.providers, namedProvider{name: name, ValueProvider: p})
+}
+
+// Returns the providers of the set followed by the ones of its
+// parents.
+func (s *CommandSet) valueProviders() []namedProvider {
+	if s.parent == nil {
+		return s.providers
+	}
+	return append(append([]namedProvider(nil), s.providers...), s.parent.valueProviders()...)
+}
+
+// Returns the path of the named sub-command from the top-level set.
+func (s *CommandSet) cmdPath(name string) string {
+	var path []string
+	for set := s; set.parent != nil; set = set.parent {
+		path = append([]string{set.cmdName}, path...)
+	}
+	return strings.TrimSpace(strings.Join(append(path, name), " "))
+}
+
+// Sets the flags of fs which are not set yet from the providers,
+// recording their sources if sources is not nil.
+func (s *CommandSet) applyProviders(fs *flag.FlagSet, cmd string, sources map[string]string) error {
+	providers := s.valueProviders()
+	if len(providers) == 0 {
+		return nil
+	}
+	set := setFlags(fs)
+	path := s.cmdPath(cmd)
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] || f.Name == "h" {
+			return
+		}
+		if isAuxFlag(f.Value) {
+			return
+		}
+		for _, p := range providers {
+			value, ok := p.Lookup(path, f.Name)
+			if !ok {
+				continue
+			}
+			if e := fs.Set(f.Name, value); e != nil {
+				err = fmt.Errorf("command: invalid value %q for flag -%s from %s: %v", value, f.Name, p.name, e)
+				return
+			}
+			if sources != nil {
+				sources[f.Name] = p.name
+			}
+			return
+		}
+	})
+	return err
+}
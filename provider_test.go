@@ -0,0 +1,64 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+// Tests if unset flags are resolved by the providers in order.
+func TestValueProviders(t *testing.T) {
+	resetForTesting("remote", "add", "-name=flag")
+	os.Setenv("GIT_REMOTE_ADD_URL", "env")
+	defer os.Unsetenv("GIT_REMOTE_ADD_URL")
+
+	var name, url, branch, tags *string
+	remote := New("remote")
+	remote.On("add", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		name = fs.String("name", "", "")
+		url = fs.String("url", "", "")
+		branch = fs.String("branch", "", "")
+		tags = fs.String("tags", "", "")
+	}}, nil)
+	OnSet("remote", "", remote)
+	SetEnvPrefix("GIT")
+	var paths []string
+	AddValueProvider("kv", ValueProviderFunc(func(cmdPath, flagName string) (string, bool) {
+		paths = append(paths, cmdPath)
+		if flagName == "tags" {
+			return "", false
+		}
+		return "kv", true
+	}))
+	AddValueProvider("fallback", ValueProviderFunc(func(cmdPath, flagName string) (string, bool) {
+		return "fallback", true
+	}))
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "flag" || *url != "env" || *branch != "kv" || *tags != "fallback" {
+		t.Errorf("unexpected values %v, %v, %v, %v", *name, *url, *branch, *tags)
+	}
+	if paths[len(paths)-1] != "remote add" {
+		t.Errorf("expected the command path %q, found %q", "remote add", paths[len(paths)-1])
+	}
+	for flagName, want := range map[string]string{"name": "flag", "url": "env", "branch": "kv", "tags": "fallback"} {
+		if found := FlagSource(flagName); found != want {
+			t.Errorf("expected the source of -%s to be %q, found %q", flagName, want, found)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+)
+
+// Resolver resolves the Cmd to run from the arguments following the
+// global flags, and returns the remaining arguments to parse as the
+// arguments of the Cmd. A nil Cmd without an error falls back to
+// looking up the registered sub-command named by the first argument.
+type Resolver func(args []string) (cmd Cmd, rest []string, err error)
+
+// SetResolver sets the Resolver used to look up sub-commands,
+// e.g. to support versioned names like `deploy@v2` or to dispatch
+// to commands that aren't registered.
+func SetResolver(r Resolver) {
+	defaultSet.SetResolver(r)
+}
+
+// SetResolver sets the Resolver of the set.
+func (s *CommandSet) SetResolver(r Resolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolver = r
+}
+
+// Returns the sub-command to run for the arguments and the
+// arguments following its name.
+func (s *CommandSet) resolve(args []string) (*cmdCont, []string, error) {
+	if s.resolver != nil {
+		cmd, rest, err := s.resolver(args)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cmd != nil {
+			return s.contOf(cmd, args[0]), rest, nil
+		}
+	}
+	cont, ok := s.cmds[args[0]]
+	if !ok {
+		return nil, nil, &UnknownCommandError{Name: args[0]}
+	}
+	return cont, args[1:], nil
+}
+
+// Returns the registered sub-command of cmd, or an unregistered one
+// with the provided name if cmd isn't registered. Lazy sub-commands
+// which aren't built yet aren't compared, so they aren't built.
+func (s *CommandSet) contOf(cmd Cmd, name string) *cmdCont {
+	if reflect.TypeOf(cmd).Comparable() {
+		for _, cont := range s.cmds {
+			if cont.command == cmd {
+				return cont
+			}
+		}
+	}
+	return &cmdCont{name: name, command: cmd}
+}
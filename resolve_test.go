@@ -0,0 +1,69 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests if sub-commands are looked up by the resolver.
+func TestSetResolver(t *testing.T) {
+	resetForTesting("deploy@v2", "-flag1", "somearg")
+
+	v1, v2 := &testCmd1{}, &testCmd1{}
+	On("deploy", "", v1, []string{"flag1"})
+	SetResolver(func(args []string) (Cmd, []string, error) {
+		switch {
+		case args[0] == "deploy@v2":
+			return v2, args[1:], nil
+		case strings.Contains(args[0], "@"):
+			return nil, nil, errors.New("unknown version")
+		}
+		return nil, nil, nil
+	})
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunErr(); err != nil {
+		t.Fatal(err)
+	}
+	if !v2.run || !*v2.flag1 || v1.run {
+		t.Error("command 'deploy@v2' was expected to run with -flag1")
+	}
+	if args := parsedArgs(); !reflect.DeepEqual(args, []string{"somearg"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	resetForTesting("deploy")
+	On("deploy", "", &testCmd1{}, []string{"flag1"})
+	SetResolver(func(args []string) (Cmd, []string, error) {
+		return nil, nil, nil
+	})
+	if _, ok := ParseErr().(Errors); !ok {
+		t.Error("the resolver was expected to fall back to the registered 'deploy'")
+	}
+
+	resetForTesting("deploy@v3")
+	On("deploy", "", &testCmd1{}, nil)
+	SetResolver(func(args []string) (Cmd, []string, error) {
+		return nil, nil, errors.New("unknown version")
+	})
+	if err := ParseErr(); err == nil || err.Error() != "unknown version" {
+		t.Errorf("expected the resolver error, found %v", err)
+	}
+}
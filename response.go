@@ -0,0 +1,146 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode"
+)
+
+// How deeply response files can refer to other response files.
+const maxResponseFileDepth = 10
+
+// EnableResponseFiles expands the @file arguments preceding a "--"
+// terminator into the arguments read from the file during Parse, e.g.
+// to get around command line length limits in CI systems. The
+// arguments are separated by whitespace or newlines and can be quoted
+// as in a shell: single quotes keep their contents as is, double
+// quotes and backslashes escape the following character. Lines
+// starting with "#" are comments. A file can refer to other response
+// files, up to 10 levels deep. An argument starting with "@@" is
+// passed with a single "@" instead. Attach the value of a flag read
+// by ValueFromFile with "=", as in -data=@file.
+func EnableResponseFiles() {
+	defaultSet.EnableResponseFiles()
+}
+
+// EnableResponseFiles expands the @file arguments of the set during
+// Parse.
+func (s *CommandSet) EnableResponseFiles() {
+	s.responseFiles = true
+}
+
+// Returns the arguments with the response files expanded.
+func (s *CommandSet) expandResponseFiles(args []string) ([]string, error) {
+	if !s.responseFiles {
+		return args, nil
+	}
+	return expandResponseFiles(args, 0)
+}
+
+func expandResponseFiles(args []string, depth int) ([]string, error) {
+	var expanded []string
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return append(expanded, args[i:]...), nil
+		case strings.HasPrefix(arg, "@@"):
+			expanded = append(expanded, arg[1:])
+		case strings.HasPrefix(arg, "@") && len(arg) > 1:
+			if depth == maxResponseFileDepth {
+				return nil, fmt.Errorf("response file %s: nested too deeply", arg[1:])
+			}
+			data, err := ioutil.ReadFile(arg[1:])
+			if err != nil {
+				return nil, err
+			}
+			fileArgs, err := splitResponseFile(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("response file %s: %v", arg[1:], err)
+			}
+			fileArgs, err = expandResponseFiles(fileArgs, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, fileArgs...)
+		default:
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded, nil
+}
+
+// Splits the contents of a response file into arguments.
+func splitResponseFile(data string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	comment := false
+	lineStart := true
+	for _, r := range data {
+		switch {
+		case comment:
+			comment = r != '\n'
+			lineStart = !comment
+			continue
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == '#' && lineStart:
+			comment = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+		lineStart = r == '\n' || (lineStart && unicode.IsSpace(r))
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests if response files are split into arguments.
+func TestSplitResponseFile(t *testing.T) {
+	for data, want := range map[string][]string{
+		"-a 1\n-b  2\n":              {"-a", "1", "-b", "2"},
+		`'a b' "c \"d\"" e\ f`:       {"a b", `c "d"`, "e f"},
+		"# comment\n  # indented\nx": {"x"},
+		"a#b ''":                     {"a#b", ""},
+	} {
+		found, err := splitResponseFile(data)
+		if err != nil || !reflect.DeepEqual(found, want) {
+			t.Errorf("%q: expected %q, found %q, %v", data, want, found, err)
+		}
+	}
+	for _, data := range []string{`"a`, `a\`} {
+		if _, err := splitResponseFile(data); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+}
+
+// Tests if response files are expanded before parsing.
+func TestResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	args := filepath.Join(dir, "args.txt")
+	more := filepath.Join(dir, "more.txt")
+	loop := filepath.Join(dir, "loop.txt")
+	for path, data := range map[string]string{
+		args: "command1 -flag1\n@" + more,
+		more: "'a b' @@c",
+		loop: "@" + loop,
+	} {
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resetForTesting("@"+args, "--", "@d")
+	cmd := &testCmd1{}
+	On("command1", "", cmd, nil)
+	EnableResponseFiles()
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if !*cmd.flag1 {
+		t.Error("flag1 should be set from the response file")
+	}
+	if want := []string{"a b", "@c", "--", "@d"}; !reflect.DeepEqual(parsedArgs(), want) {
+		t.Errorf("expected %q, found %q", want, parsedArgs())
+	}
+	resetForTesting("@" + loop)
+	EnableResponseFiles()
+	if err := ParseErr(); err == nil {
+		t.Error("expected an error for recursive response files")
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+// SetRoot sets the Cmd run if no sub-command name is given, e.g.
+// `program -v`, instead of printing the usage and exiting with 1.
+// Its flags are defined on the global flag set, and it receives the
+// arguments if no sub-commands are registered.
+func SetRoot(command Cmd) {
+	defaultSet.SetRoot(command)
+}
+
+// SetRoot sets the root Cmd of the set.
+func (s *CommandSet) SetRoot(command Cmd) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	command.Flags(s.Flags())
+	s.root = &cmdCont{command: command}
+}
+
+// Returns the result running the root Cmd with args, nil if there
+// is no root.
+func (s *CommandSet) rootResult(args []string) *parseResult {
+	if s.root == nil {
+		return nil
+	}
+	return &parseResult{set: s, cont: s.root, args: args}
+}
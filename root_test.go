@@ -0,0 +1,47 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests if the root runs if no sub-command name is given.
+func TestSetRoot(t *testing.T) {
+	resetForTesting("-flag1")
+
+	root := &testCmd1{}
+	SetRoot(root)
+	On("command2", "", &testCmd2{}, nil)
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	if !root.run || !*root.flag1 {
+		t.Error("the root was expected to run with -flag1")
+	}
+
+	resetForTesting("a", "b")
+	root = &testCmd1{}
+	SetRoot(root)
+	Parse()
+	Run()
+	if !root.run {
+		t.Error("the root was expected to run")
+	}
+	if args := parsedArgs(); !reflect.DeepEqual(args, []string{"a", "b"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
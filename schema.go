@@ -0,0 +1,156 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigSchema returns a JSON Schema describing the config files of
+// EnableConfigFlag, from the flags of the sub-commands: the top-level
+// keys are the global flags and the flags of any sub-command, the
+// tables named by the sub-commands and nested sets hold their flags,
+// and the profiles table holds the profiles if they are enabled.
+// Editors and CI checks can validate config files against it.
+func ConfigSchema() ([]byte, error) {
+	return defaultSet.ConfigSchema()
+}
+
+// ConfigSchema returns a JSON Schema describing the config files of
+// the set.
+func (s *CommandSet) ConfigSchema() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	properties := s.configProperties()
+	if s.profilesEnabled {
+		properties[profilesKey] = map[string]interface{}{
+			"type":                 "object",
+			"description":          "named presets of flag values",
+			"additionalProperties": tableSchema("", s.configProperties()),
+		}
+	}
+	schema := tableSchema("", properties)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = s.program()
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// Returns the properties of the config file of the set: the global
+// and persistent flags, the flags of any sub-command and the tables
+// of the sub-commands and nested sets.
+func (s *CommandSet) configProperties() map[string]interface{} {
+	properties := make(map[string]interface{})
+	addFlags := func(fs *flag.FlagSet, table map[string]interface{}) {
+		fs.VisitAll(func(f *flag.Flag) {
+			if isAuxFlag(f.Value) {
+				return
+			}
+			if f.Name == configFlagName || f.Name == profileFlagName {
+				return
+			}
+			table[f.Name] = flagSchema(f)
+			if _, ok := properties[f.Name]; !ok {
+				properties[f.Name] = table[f.Name]
+			}
+		})
+	}
+	addFlags(s.Flags(), properties)
+	if s.persistent != nil {
+		addFlags(s.persistent, properties)
+	}
+	for _, name := range s.sortedCmdNames() {
+		cont := s.cmds[name]
+		if n, ok := cont.cmd().(*nestedCmd); ok {
+			properties[name] = tableSchema(cont.desc, n.set.configProperties())
+			continue
+		}
+		if cont.disableFlagParsing {
+			continue
+		}
+		table := make(map[string]interface{})
+		addFlags(s.flagSet(cont, flag.ContinueOnError), table)
+		properties[name] = tableSchema(cont.desc, table)
+	}
+	return properties
+}
+
+// Returns the schema of a table with the properties.
+func tableSchema(description string, properties map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if description != "" {
+		schema["description"] = description
+	}
+	return schema
+}
+
+// Returns the schema of the values of a flag.
+func flagSchema(f *flag.Flag) map[string]interface{} {
+	schema := map[string]interface{}{"type": "string"}
+	if usage := strings.TrimSpace(f.Usage); usage != "" {
+		schema["description"] = usage
+	}
+	switch v := unwrapValue(f.Value).(type) {
+	case *choiceValue:
+		schema["enum"] = v.choices
+	case *stringSlice:
+		schema["type"] = "array"
+		schema["items"] = map[string]interface{}{"type": "string"}
+		return schema
+	case *intSlice:
+		schema["type"] = "array"
+		schema["items"] = map[string]interface{}{"type": "integer"}
+		return schema
+	case *countValue:
+		schema["type"] = "integer"
+	case flag.Getter:
+		switch v.Get().(type) {
+		case bool:
+			schema["type"] = "boolean"
+		case int, int64, uint, uint64:
+			schema["type"] = "integer"
+		case float64:
+			schema["type"] = "number"
+		case time.Duration:
+			schema["format"] = "duration"
+		}
+	}
+	if f.DefValue != "" {
+		schema["default"] = typedDefault(schema["type"], f.DefValue)
+	}
+	return schema
+}
+
+// Returns the default value as the JSON type of the schema.
+func typedDefault(typ interface{}, def string) interface{} {
+	switch typ {
+	case "boolean":
+		if b, err := strconv.ParseBool(def); err == nil {
+			return b
+		}
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(def, 64); err == nil {
+			return n
+		}
+	}
+	return def
+}
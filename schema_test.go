@@ -0,0 +1,109 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests if the config schema describes the flags.
+func TestConfigSchema(t *testing.T) {
+	resetForTesting()
+
+	On("serve", "starts the server", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.Int("port", 8080, "listen port")
+		fs.Bool("tls", false, "")
+		fs.Duration("timeout", time.Second, "")
+		Choice(fs, "format", []string{"json", "text"}, "text", "")
+		StringSlice(fs, "tag", "", "")
+		HideFlag(fs, "tls")
+	}}, nil)
+	EnableConfigFlag()
+	data, err := ConfigSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Type       string
+			Properties map[string]map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	serve := schema.Properties["serve"]
+	if serve.Type != "object" {
+		t.Fatalf("expected a table for serve, found %s", data)
+	}
+	want := map[string]map[string]interface{}{
+		"port":    {"type": "integer", "description": "listen port", "default": 8080.0},
+		"tls":     {"type": "boolean", "default": false},
+		"timeout": {"type": "string", "format": "duration", "default": "1s"},
+		"format":  {"type": "string", "enum": []interface{}{"json", "text"}, "default": "text", "description": "(one of json, text)"},
+		"tag":     {"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "(can be repeated)"},
+	}
+	if !reflect.DeepEqual(serve.Properties, want) {
+		t.Errorf("expected %v, found %v", want, serve.Properties)
+	}
+	if schema.Properties["port"].Type != "integer" {
+		t.Errorf("expected a top-level port key, found %s", data)
+	}
+}
+
+// Tests if the config schema describes the global flags, the profiles
+// and the tables of nested sets.
+func TestConfigSchemaNested(t *testing.T) {
+	resetForTesting()
+
+	flag.Bool("verbose", false, "")
+	remote := New("remote")
+	remote.Flags().String("name", "", "")
+	remote.On("add", "", &testCmd1{}, nil)
+	OnSet("remote", "manages remotes", remote)
+	On("command1", "", &testCmd1{}, nil)
+	EnableConfigFlag()
+	EnableProfiles()
+	data, err := ConfigSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type table struct {
+		Type                 string
+		Properties           map[string]*table
+		AdditionalProperties interface{}
+	}
+	var schema table
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Properties["verbose"] == nil {
+		t.Errorf("expected a key for the global flag, found %s", data)
+	}
+	if r := schema.Properties["remote"]; r == nil || r.Properties["name"] == nil || r.Properties["add"] == nil || r.Properties["add"].Properties["flag1"] == nil {
+		t.Errorf("expected a table for the nested set, found %s", data)
+	}
+	profiles := schema.Properties["profiles"]
+	if profiles == nil || profiles.Type != "object" {
+		t.Fatalf("expected a profiles table, found %s", data)
+	}
+	if profile, ok := profiles.AdditionalProperties.(map[string]interface{}); !ok || profile["properties"].(map[string]interface{})["verbose"] == nil {
+		t.Errorf("expected the profiles to have the layout of the config file, found %s", data)
+	}
+}
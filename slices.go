@@ -0,0 +1,118 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// StringSlice defines a string flag which can be repeated, e.g.
+// -tag a -tag b, accumulating its values in order. If sep is not
+// empty, each value is also split by it, e.g. -tag a,b with ",".
+func StringSlice(fs *flag.FlagSet, name, sep, usage string) *[]string {
+	v := &stringSlice{values: new([]string), sep: sep}
+	fs.Var(v, name, repeatableUsage(usage, sep))
+	return v.values
+}
+
+// IntSlice defines an int flag which can be repeated like
+// StringSlice.
+func IntSlice(fs *flag.FlagSet, name, sep, usage string) *[]int {
+	v := &intSlice{values: new([]int), sep: sep}
+	fs.Var(v, name, repeatableUsage(usage, sep))
+	return v.values
+}
+
+func repeatableUsage(usage, sep string) string {
+	if sep == "" {
+		return usage + " (can be repeated)"
+	}
+	return usage + " (can be repeated or " + strconv.Quote(sep) + "-separated)"
+}
+
+// Returns the values of s, split by sep if it's not empty.
+func splitValue(s, sep string) []string {
+	if sep == "" {
+		return []string{s}
+	}
+	return strings.Split(s, sep)
+}
+
+type stringSlice struct {
+	values *[]string
+	sep    string
+	// whether the values are defaults, replaced once the flag is set.
+	replace bool
+}
+
+func (v *stringSlice) String() string {
+	if v == nil || v.values == nil {
+		return ""
+	}
+	return strings.Join(*v.values, ",")
+}
+
+func (v *stringSlice) Set(s string) error {
+	if v.replace {
+		*v.values, v.replace = nil, false
+	}
+	*v.values = append(*v.values, splitValue(s, v.sep)...)
+	return nil
+}
+
+type intSlice struct {
+	values *[]int
+	sep    string
+	// whether the values are defaults, replaced once the flag is set.
+	replace bool
+}
+
+func (v *intSlice) String() string {
+	if v == nil || v.values == nil {
+		return ""
+	}
+	strs := make([]string, len(*v.values))
+	for i, n := range *v.values {
+		strs[i] = strconv.Itoa(n)
+	}
+	return strings.Join(strs, ",")
+}
+
+func (v *intSlice) Set(s string) error {
+	if v.replace {
+		*v.values, v.replace = nil, false
+	}
+	for _, str := range splitValue(s, v.sep) {
+		n, err := strconv.Atoi(strings.TrimSpace(str))
+		if err != nil {
+			return err
+		}
+		*v.values = append(*v.values, n)
+	}
+	return nil
+}
+
+// Marks the values of v the defaults of the slice flag, replaced
+// instead of accumulated once it's set.
+func markSliceDefault(v flag.Value) {
+	switch v := v.(type) {
+	case *stringSlice:
+		v.replace = true
+	case *intSlice:
+		v.replace = true
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+// Tests if repeated slice flags accumulate their values.
+func TestSliceFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	tags := StringSlice(fs, "tag", "", "")
+	ports := IntSlice(fs, "port", ",", "")
+	if err := fs.Parse([]string{"-tag", "a,b", "-port", "80,443", "-tag", "c", "-port=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a,b", "c"}; !reflect.DeepEqual(*tags, want) {
+		t.Errorf("expected tags %v, found %v", want, *tags)
+	}
+	if want := []int{80, 443, 8080}; !reflect.DeepEqual(*ports, want) {
+		t.Errorf("expected ports %v, found %v", want, *ports)
+	}
+	if err := fs.Parse([]string{"-port", "x"}); err == nil {
+		t.Error("an error was expected for a malformed int")
+	}
+}
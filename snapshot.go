@@ -0,0 +1,109 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// FlagValues returns the effective values of the flags of the
+// sub-command matched by Parse keyed by their names, e.g. to log
+// or serialize the configuration it runs with. Defaults are included,
+// aliases, deprecated names and hidden flags are not. It returns nil
+// if no sub-command is matched.
+func FlagValues() map[string]string {
+	return defaultSet.FlagValues()
+}
+
+// FlagValues returns the effective values of the flags of the
+// sub-command of the set matched by Parse.
+func (s *CommandSet) FlagValues() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matching == nil || s.matching.flags == nil {
+		return nil
+	}
+	fs := s.matching.flags
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if isAuxFlag(f.Value) {
+			return
+		}
+		if f.Name != "h" && !isHiddenFlag(fs, f) {
+			values[f.Name] = f.Value.String()
+		}
+	})
+	return values
+}
+
+// DecodeFlags sets the fields of the struct v points to with a
+// `flag` tag, as in BindFlags, to the values of the flags returned
+// by FlagValues.
+func DecodeFlags(v interface{}) error {
+	return defaultSet.DecodeFlags(v)
+}
+
+// DecodeFlags sets the fields of the struct v points to, to the
+// values of the flags of the matched sub-command of the set.
+func (s *CommandSet) DecodeFlags(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("command: DecodeFlags of %T, a pointer to a struct was expected", v)
+	}
+	return decodeFields(rv.Elem(), s.FlagValues())
+}
+
+// Sets the fields of the struct value rv tagged with a flag name
+// in values.
+func decodeFields(rv reflect.Value, values map[string]string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field, fv := rt.Field(i), rv.Field(i)
+		name, ok := field.Tag.Lookup("flag")
+		if !ok {
+			if field.Anonymous && fv.Kind() == reflect.Struct {
+				if err := decodeFields(fv, values); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		value, ok := values[name]
+		if !ok || !fv.CanSet() {
+			continue
+		}
+		sep := field.Tag.Get("sep")
+		if fv.Kind() == reflect.Slice && sep == "" {
+			// slices are formatted comma-separated.
+			sep = ","
+		}
+		scratch := flag.NewFlagSet(name, flag.ContinueOnError)
+		if err := bindField(scratch, fv, name, "", sep); err != nil {
+			return fmt.Errorf("command: field %s: %v", field.Name, err)
+		}
+		if fv.Kind() == reflect.Slice {
+			fv.Set(reflect.Zero(fv.Type()))
+			if value == "" {
+				continue
+			}
+		}
+		if err := scratch.Set(name, value); err != nil {
+			return fmt.Errorf("command: field %s: %v", field.Name, err)
+		}
+	}
+	return nil
+}
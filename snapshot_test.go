@@ -0,0 +1,55 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests if the flag values of the matched sub-command are collected.
+func TestFlagValues(t *testing.T) {
+	resetForTesting("serve", "-port=9090", "-t", "a", "-t", "b")
+
+	On("serve", "", &flagsFuncCmd{flags: func(fs *flag.FlagSet) {
+		fs.Int("port", 8080, "")
+		fs.Duration("timeout", time.Second, "")
+		StringSlice(fs, "tag", "", "")
+		Alias(fs, "t", "tag")
+		fs.Bool("debug", false, "")
+		HideFlag(fs, "debug")
+	}}, nil)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"port": "9090", "timeout": "1s", "tag": "a,b"}
+	if found := FlagValues(); !reflect.DeepEqual(found, want) {
+		t.Errorf("expected %v, found %v", want, found)
+	}
+	var opts struct {
+		Port    int           `flag:"port"`
+		Timeout time.Duration `flag:"timeout"`
+		Tags    []string      `flag:"tag"`
+		Other   string
+	}
+	if err := DecodeFlags(&opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Port != 9090 || opts.Timeout != time.Second || !reflect.DeepEqual(opts.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected snapshot %+v", opts)
+	}
+}
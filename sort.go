@@ -0,0 +1,69 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"sort"
+)
+
+// SortMode determines the order sub-commands are listed in the usage.
+type SortMode int
+
+const (
+	// SortAlphabetical lists sub-commands by name.
+	SortAlphabetical SortMode = iota
+	// SortRegistration lists sub-commands in the order they are registered.
+	SortRegistration
+	// SortCustom lists sub-commands with a user provided comparator.
+	SortCustom
+)
+
+// SetCommandSort sets the order sub-commands are listed in the usage.
+// less compares two sub-command names and is only used by SortCustom.
+func SetCommandSort(mode SortMode, less func(a, b string) bool) {
+	defaultSet.SetCommandSort(mode, less)
+}
+
+// SetCommandSort sets the order sub-commands of the set are listed in.
+func (s *CommandSet) SetCommandSort(mode SortMode, less func(a, b string) bool) {
+	s.sortMode = mode
+	s.sortLess = less
+}
+
+// Returns the names of the visible sub-commands in the sort order.
+func (s *CommandSet) sortedCmdNames() []string {
+	var names []string
+	for name, cont := range s.cmds {
+		// aliases are listed in the sub-command usage.
+		if !cont.hidden && name == cont.name {
+			names = append(names, name)
+		}
+	}
+	less := func(i, j int) bool {
+		return names[i] < names[j]
+	}
+	switch {
+	case s.sortMode == SortRegistration:
+		less = func(i, j int) bool {
+			return s.cmds[names[i]].order < s.cmds[names[j]].order
+		}
+	case s.sortMode == SortCustom && s.sortLess != nil:
+		less = func(i, j int) bool {
+			return s.sortLess(names[i], names[j])
+		}
+	}
+	sort.Slice(names, less)
+	return names
+}
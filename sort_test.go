@@ -0,0 +1,44 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Tests the order sub-commands are listed in for each sort mode.
+func TestSortedCmdNames(t *testing.T) {
+	resetForTesting()
+
+	On("status", "", &testCmd1{}, []string{})
+	On("add", "", &testCmd1{}, []string{})
+	On("commit", "", &testCmd1{}, []string{})
+	tests := []struct {
+		mode     SortMode
+		less     func(a, b string) bool
+		expected string
+	}{
+		{SortAlphabetical, nil, "[add commit status]"},
+		{SortRegistration, nil, "[status add commit]"},
+		{SortCustom, func(a, b string) bool { return a > b }, "[status commit add]"},
+	}
+	for _, tt := range tests {
+		SetCommandSort(tt.mode, tt.less)
+		if found := fmt.Sprint(defaultSet.sortedCmdNames()); found != tt.expected {
+			t.Errorf("mode %v: expected %v, found %v", tt.mode, tt.expected, found)
+		}
+	}
+}
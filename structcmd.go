@@ -0,0 +1,103 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// FromStruct registers a sub-command whose flags are bound to the
+// fields of the struct v points to as by BindFlags, and whose
+// positional arguments are bound to the fields with an `arg` tag,
+// described by their `usage` tag, e.g.
+//
+//	type copyOptions struct {
+//		Force bool     `flag:"f" usage:"overwrites the destination"`
+//		Src   []string `arg:"src" usage:"files to copy"`
+//		Dst   string   `arg:"dst" usage:"directory to copy to"`
+//	}
+//
+// A slice field is a variadic positional. The fields are reset to
+// their values at registration each time the flags are defined, i.e.
+// before the arguments are parsed and the usage is printed. run is
+// called with the positional arguments.
+func FromStruct(name, description string, v interface{}, run func(args []string), opts ...Option) {
+	defaultSet.FromStruct(name, description, v, run, opts...)
+}
+
+// FromStruct registers a sub-command of the set bound to the
+// struct v points to.
+func (s *CommandSet) FromStruct(name, description string, v interface{}, run func(args []string), opts ...Option) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("command: FromStruct of %T, a pointer to a struct was expected", v))
+	}
+	cmd := &structCmd{v: v, initial: reflect.New(rv.Elem().Type()).Elem(), run: run}
+	cmd.initial.Set(rv.Elem())
+	s.On(name, description, cmd, nil, append(structArgs(rv.Elem()), opts...)...)
+}
+
+// structCmd is the sub-command registered by FromStruct.
+type structCmd struct {
+	v       interface{}
+	initial reflect.Value
+	run     func(args []string)
+}
+
+func (cmd *structCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	reflect.ValueOf(cmd.v).Elem().Set(cmd.initial)
+	BindFlags(fs, cmd.v)
+	return fs
+}
+
+func (cmd *structCmd) Run(args []string) {
+	cmd.run(args)
+}
+
+// Returns the options declaring the positionals bound to the fields
+// of the struct value rv with an `arg` tag.
+func structArgs(rv reflect.Value) []Option {
+	var names []string
+	vars := make(map[string]flag.Value)
+	var opts []Option
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field, fv := rt.Field(i), rv.Field(i)
+		name, ok := field.Tag.Lookup("arg")
+		if !ok || !fv.CanSet() {
+			continue
+		}
+		scratch := flag.NewFlagSet(name, flag.ContinueOnError)
+		if err := bindField(scratch, fv, name, "", field.Tag.Get("sep")); err != nil {
+			panic(fmt.Sprintf("command: field %s: %v", field.Name, err))
+		}
+		vars[name] = scratch.Lookup(name).Value
+		if fv.Kind() == reflect.Slice {
+			name += variadicSuffix
+		}
+		names = append(names, name)
+		if usage := field.Tag.Get("usage"); usage != "" {
+			opts = append(opts, ArgHelp(name, usage))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return append(opts, Args(names...), func(cont *cmdCont) {
+		cont.argVars = vars
+	})
+}
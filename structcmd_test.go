@@ -0,0 +1,56 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type copyOptions struct {
+	Force   bool     `flag:"f" usage:"overwrites the destination"`
+	Retries int      `arg:"retries" usage:"number of retries"`
+	Src     []string `arg:"src" usage:"files to copy"`
+	Dst     string   `arg:"dst"`
+}
+
+// Tests if a sub-command is derived from a struct.
+func TestFromStruct(t *testing.T) {
+	resetForTesting("cp", "-f", "3", "a", "b", "dir")
+
+	var opts copyOptions
+	var ran bool
+	FromStruct("cp", "copies files", &opts, func(args []string) { ran = true })
+	if err := ParseAndRunE(); err != nil {
+		t.Fatal(err)
+	}
+	want := copyOptions{Force: true, Retries: 3, Src: []string{"a", "b"}, Dst: "dir"}
+	if !ran || !reflect.DeepEqual(opts, want) {
+		t.Errorf("expected %+v to run, found %+v", want, opts)
+	}
+	usage, _ := SubcommandUsageString("cp")
+	for _, s := range []string{"cmd cp <retries> <src...> <dst>:", "overwrites the destination", "<src...>\n    \tfiles to copy"} {
+		if !strings.Contains(usage, s) {
+			t.Errorf("expected the usage to contain %q, found %q", s, usage)
+		}
+	}
+
+	resetForTesting("cp", "many", "a", "dir")
+	FromStruct("cp", "copies files", &opts, func(args []string) {})
+	if err := ParseErr(); err == nil || !strings.Contains(err.Error(), "argument <retries>") {
+		t.Errorf("expected a conversion error, found %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Maximum edit distance of a suggested sub-command name.
+const suggestDistance = 2
+
+// Prints that name is not a sub-command of path, followed by
+// the sub-commands with a similar name.
+func (s *CommandSet) printUnknown(w io.Writer, path, name string) {
+	fmt.Fprintf(w, "unknown command %q for %q\n", name, path)
+	if suggestions := s.suggest(name); len(suggestions) > 0 {
+		fmt.Fprintf(w, "\nDid you mean this?\n")
+		for _, s := range suggestions {
+			fmt.Fprintf(w, "\t%s\n", s)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// Returns the visible sub-commands whose name is similar
+// to or starts with name.
+func (s *CommandSet) suggest(name string) []string {
+	if name == "" {
+		return nil
+	}
+	var suggestions []string
+	for n, cont := range s.cmds {
+		if cont.hidden {
+			continue
+		}
+		if strings.HasPrefix(n, name) || distance(n, name) <= suggestDistance {
+			suggestions = append(suggestions, n)
+		}
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+// Returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,42 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Tests if similar sub-command names are suggested.
+func TestUnknownCommandSuggestions(t *testing.T) {
+	resetForTesting()
+
+	On("status", "", &testCmd1{}, []string{})
+	On("stash", "", &testCmd1{}, []string{})
+	On("commit", "", &testCmd1{}, []string{})
+	var buf bytes.Buffer
+	defaultSet.printUnknown(&buf, "cmd", "stauts")
+	out := buf.String()
+	if !strings.Contains(out, `unknown command "stauts" for "cmd"`) {
+		t.Errorf("unexpected message %q", out)
+	}
+	if !strings.Contains(out, "Did you mean this?\n\tstatus\n") {
+		t.Errorf("status was expected to be suggested, found %q", out)
+	}
+	if strings.Contains(out, "commit") {
+		t.Errorf("commit was not expected to be suggested, found %q", out)
+	}
+}
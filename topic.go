@@ -0,0 +1,96 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Name of the sub-command displaying help topics.
+const helpCmdName = "help"
+
+type helpTopic struct {
+	title string
+	body  string
+}
+
+// RegisterHelpTopic registers a help topic for documentation that is
+// not tied to a sub-command. Topics are listed in the usage and
+// displayed by the help sub-command, e.g. `program help auth`, which
+// is registered with the first topic unless a help command exists.
+func RegisterHelpTopic(name, title, body string) {
+	defaultSet.RegisterHelpTopic(name, title, body)
+}
+
+// RegisterHelpTopic registers a help topic in the set.
+func (s *CommandSet) RegisterHelpTopic(name, title, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topics[name] = &helpTopic{title: title, body: body}
+	if _, ok := s.cmds[helpCmdName]; !ok {
+		s.on(helpCmdName, "displays help about a command or topic", &helpCmd{set: s}, nil)
+	}
+}
+
+// Prints the list of help topics, if there are any.
+func (s *CommandSet) printTopics(w io.Writer) {
+	if len(s.topics) == 0 {
+		return
+	}
+	var names []string
+	for name := range s.topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "\n%s\n", UsageStrings.Topics)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-15s %s\n", name, s.topics[name].title)
+	}
+}
+
+// helpCmd displays the help of a sub-command or a help topic.
+type helpCmd struct {
+	set *CommandSet
+}
+
+func (cmd *helpCmd) Flags(fs *flag.FlagSet) *flag.FlagSet {
+	return fs
+}
+
+func (cmd *helpCmd) Run(args []string) {
+	cmd.set.printHelp(cmd.set.stderr(), args)
+}
+
+// Prints the help of the named sub-command or help topic,
+// or the usage if no name is given.
+func (s *CommandSet) printHelp(w io.Writer, args []string) {
+	if len(args) == 0 {
+		s.printUsage(w)
+		return
+	}
+	if topic, ok := s.topics[args[0]]; ok {
+		fmt.Fprintf(w, "%s\n\n%s\n", topic.title, topic.body)
+		return
+	}
+	if cont, ok := s.cmds[args[0]]; ok {
+		s.printSubcommandUsage(w, cont)
+		return
+	}
+	fmt.Fprintf(w, "unknown help topic %q\n\n", args[0])
+	s.printUsage(w)
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests if help topics are listed in the usage.
+func TestHelpTopicUsage(t *testing.T) {
+	resetForTesting()
+
+	On("command1", "", &testCmd1{}, []string{})
+	RegisterHelpTopic("auth", "Authenticating with the server", "Set the TOKEN environment variable.")
+	usage := UsageString()
+	if !strings.Contains(usage, "additional help topics:\n  auth") {
+		t.Errorf("usage was expected to list the topic, found %q", usage)
+	}
+	if !strings.Contains(usage, "help ") {
+		t.Errorf("usage was expected to list the help command, found %q", usage)
+	}
+}
+
+// Tests if the help command displays topics and commands.
+func TestHelpTopic(t *testing.T) {
+	resetForTesting("help", "auth")
+
+	On("command1", "", &testCmd1{}, []string{})
+	RegisterHelpTopic("auth", "Authenticating with the server", "Set the TOKEN environment variable.")
+	Parse()
+	out := captureStderr(Run)
+	if !strings.Contains(out, "Set the TOKEN environment variable.") {
+		t.Errorf("help was expected to display the topic, found %q", out)
+	}
+
+	resetForTesting("help", "command1")
+	On("command1", "", &testCmd1{}, []string{})
+	RegisterHelpTopic("auth", "Authenticating with the server", "Set the TOKEN environment variable.")
+	Parse()
+	out = captureStderr(Run)
+	if !strings.Contains(out, "-flag1") {
+		t.Errorf("help was expected to display the command usage, found %q", out)
+	}
+}
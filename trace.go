@@ -0,0 +1,84 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EnableTrace registers a global -trace flag during Parse. If the
+// flag is set, Run prints the matching subcommand, the parsed flag
+// values with their sources and the leftover arguments to stderr
+// before running it.
+func EnableTrace() {
+	defaultSet.EnableTrace()
+}
+
+// EnableTrace registers a global -trace flag on the set during Parse.
+func (s *CommandSet) EnableTrace() {
+	s.traceEnabled = true
+}
+
+const traceFlagName = "trace"
+
+// Defines the -trace flag on fs if trace is enabled, once for
+// repeated parses.
+func (s *CommandSet) defineTraceFlag(fs *flag.FlagSet) {
+	if s.traceEnabled && fs.Lookup(traceFlagName) == nil {
+		fs.Bool(traceFlagName, false, "prints the resolved command, flags and arguments")
+	}
+}
+
+// Reports whether the -trace flag of the set, or of a set it's
+// nested in, is set.
+func (s *CommandSet) tracing() bool {
+	for set := s; set != nil; set = set.parent {
+		if !set.traceEnabled {
+			continue
+		}
+		if f := set.Flags().Lookup(traceFlagName); f != nil && f.Value.String() == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// Prints the resolved subcommand, flags and arguments.
+func (s *CommandSet) printTrace(w io.Writer, res *parseResult) {
+	// the sets leading to the sub-command, from the root.
+	var sets []*CommandSet
+	for set := s; set != nil; set = set.parent {
+		sets = append([]*CommandSet{set}, sets...)
+	}
+	var path []string
+	for _, set := range sets[1:] {
+		path = append(path, set.cmdName)
+	}
+	fmt.Fprintf(w, "trace: command %s\n", strings.Join(append(path, res.cont.name), " "))
+	for _, set := range sets {
+		set.Flags().VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(w, "trace: global flag -%s=%s\n", f.Name, f.Value)
+		})
+	}
+	if res.flags != nil {
+		res.flags.VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(w, "trace: flag -%s=%s (%s)\n", f.Name, f.Value, res.source(f.Name))
+		})
+	}
+	fmt.Fprintf(w, "trace: args %q\n", res.args)
+}
@@ -0,0 +1,78 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Tests if the trace contains the resolved command, flags and args.
+func TestTrace(t *testing.T) {
+	resetForTesting("-trace", "command1", "-flag1=true", "somearg")
+
+	EnableTrace()
+	On("command1", "", &testCmd1{}, []string{})
+	Parse()
+	if !defaultSet.tracing() {
+		t.Fatal("trace flag was expected to be set")
+	}
+	var buf bytes.Buffer
+	defaultSet.printTrace(&buf, defaultSet.matching)
+	for _, s := range []string{"command command1", "global flag -trace=true", "flag -flag1=true", `args ["somearg"]`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("trace was expected to contain %q, found %q", s, buf.String())
+		}
+	}
+}
+
+// Tests if the trace flag is defined once for repeated parses.
+func TestTraceParseTwice(t *testing.T) {
+	set := New("tool")
+	set.EnableTrace()
+	set.On("command1", "", &testCmd1{}, nil)
+	if err := set.ParseErr([]string{"-trace", "command1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.ParseErr([]string{"-trace", "command1"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests if the trace of a nested sub-command is printed with its
+// full path.
+func TestTraceNested(t *testing.T) {
+	resetForTesting("-trace", "remote", "-v", "add", "origin")
+
+	EnableTrace()
+	remote := New("remote")
+	remote.Flags().Bool("v", false, "")
+	remote.On("add", "", &testCmd1{}, nil)
+	OnSet("remote", "", remote)
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	out := captureStderr(func() {
+		if err := RunErr(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	for _, s := range []string{"command remote add", "global flag -trace=true", "global flag -v=true", `args ["origin"]`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("trace was expected to contain %q, found %q", s, out)
+		}
+	}
+}
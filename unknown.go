@@ -0,0 +1,59 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"strings"
+)
+
+// PassUnknownFlags makes Parse pass the flags the sub-command doesn't
+// define to its runnable instead of failing, e.g. for commands that
+// wrap other programs. They precede the positional arguments, as
+// provided. The value of an unknown flag is only passed along with it
+// if it's provided as -flag=value.
+func PassUnknownFlags() Option {
+	return func(cont *cmdCont) {
+		cont.passUnknownFlags = true
+	}
+}
+
+// Splits the flags preceding the positional arguments into the ones
+// fs defines, followed by the rest of the arguments, and the ones it
+// doesn't.
+func splitUnknownFlags(fs *flag.FlagSet, arguments []string) (known, unknown []string) {
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			return append(known, arguments[i:]...), unknown
+		}
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.Index(name, "=")]
+		}
+		f := fs.Lookup(name)
+		if f == nil && name != "help" {
+			unknown = append(unknown, arg)
+			continue
+		}
+		known = append(known, arg)
+		if f != nil && !hasValue && !isBoolFlag(f) && i+1 < len(arguments) {
+			i++
+			known = append(known, arguments[i])
+		}
+	}
+	return known, unknown
+}
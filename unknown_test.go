@@ -0,0 +1,38 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests if unknown flags are passed to the runnable.
+func TestPassUnknownFlags(t *testing.T) {
+	resetForTesting("wrap", "-it", "-flag1", "--name=x", "ubuntu", "-flag1")
+
+	c1 := &testCmd1{}
+	On("wrap", "", c1, nil, PassUnknownFlags())
+	if err := ParseErr(); err != nil {
+		t.Fatal(err)
+	}
+	if !*c1.flag1 {
+		t.Error("-flag1 was expected to be set")
+	}
+	want := []string{"-it", "--name=x", "ubuntu", "-flag1"}
+	if args := parsedArgs(); !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, found %v", want, args)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Errors lists all of the problems found while validating
+// the arguments of a sub-command.
+type Errors []error
+
+func (errs Errors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("%d problems found:\n%s", len(errs), strings.Join(msgs, "\n"))
+}
+
+// Unwrap returns the problems, so they can be inspected with
+// errors.As.
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// MissingFlagsError is the problem reported if required flags of a
+// sub-command are not set.
+type MissingFlagsError struct {
+	Command string
+	// Flags are the missing flag names, sorted and without a dash.
+	Flags []string
+
+	msg string
+}
+
+func (e *MissingFlagsError) Error() string {
+	return e.msg
+}
+
+// Runs all of the checks on the parsed flags and positionals of
+// the sub-command, returns every violation found.
+func (s *CommandSet) validate(cont *cmdCont, fs *flag.FlagSet, args []string) Errors {
+	var errs Errors
+	if err := s.checkRequiredFlags(cont, fs); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, cont.checkConstraints(fs)...)
+	if err := cont.checkArgs(args); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, cont.validateArgs(args)...)
+		errs = append(errs, cont.bindArgs(fs, args)...)
+	}
+	return errs
+}
+
+// Returns a MissingFlagsError listing the required flags that
+// are not set.
+func (s *CommandSet) checkRequiredFlags(cont *cmdCont, fs *flag.FlagSet) error {
+	flagMap := make(map[string]bool)
+	for _, flagName := range requiredFlags(cont, fs) {
+		flagMap[flagName] = true
+	}
+	for name := range setFlags(fs) {
+		delete(flagMap, name)
+	}
+	if len(flagMap) == 0 {
+		return nil
+	}
+	var missing []string
+	for flagName := range flagMap {
+		missing = append(missing, flagName)
+	}
+	sort.Strings(missing)
+	return &MissingFlagsError{Command: cont.name, Flags: missing, msg: s.requiredFlagErrorf(cont.name, missing)}
+}
+
+// SetRequiredFlagErrorFunc sets the function building the message
+// reporting the required flags missing from the named sub-command,
+// e.g. to render it as JSON. Flag names are sorted and have no dash.
+func SetRequiredFlagErrorFunc(fn func(cmd string, missing []string) string) {
+	defaultSet.SetRequiredFlagErrorFunc(fn)
+}
+
+// SetRequiredFlagErrorFunc sets the function building the message
+// reporting the missing required flags of the set.
+func (s *CommandSet) SetRequiredFlagErrorFunc(fn func(cmd string, missing []string) string) {
+	s.requiredFlagErrorf = fn
+}
+
+func defaultRequiredFlagError(cmd string, missing []string) string {
+	return "missing required flags: -" + strings.Join(missing, ", -")
+}
+
+// SetValidator sets a function checking the values of the flag name
+// of fs as they are parsed, e.g. a range or a pattern. A value it
+// rejects fails the parsing with the error attached to the flag
+// name. The default value is not checked. It panics if name is not
+// defined.
+func SetValidator(fs *flag.FlagSet, name string, fn func(value string) error) {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("command: validator of undefined flag -%s", name))
+	}
+	f.Value = &validatedValue{valueWrapper: valueWrapper{f.Value}, validate: fn}
+}
+
+// validatedValue checks the values of the flag it wraps.
+type validatedValue struct {
+	valueWrapper
+	validate func(value string) error
+}
+
+func (v *validatedValue) Set(s string) error {
+	if err := v.validate(s); err != nil {
+		return err
+	}
+	return v.Value.Set(s)
+}
+
+// RequireFlag marks the flags of fs with the names required, like
+// the required flags passed to On, e.g. in the Flags of a sub-command.
+// It panics if a name is not defined.
+func RequireFlag(fs *flag.FlagSet, names ...string) {
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			panic(fmt.Sprintf("command: requiring undefined flag -%s", name))
+		}
+		f.Value = &requiredValue{valueWrapper{f.Value}}
+	}
+}
+
+// requiredValue marks the flag it wraps required.
+type requiredValue struct {
+	valueWrapper
+}
+
+// Returns the required flags of the sub-command, the ones passed to
+// On followed by the ones of fs marked by RequireFlag.
+func requiredFlags(cont *cmdCont, fs *flag.FlagSet) []string {
+	names := append([]string(nil), cont.requiredFlags...)
+	fs.VisitAll(func(f *flag.Flag) {
+		required := wrapsValue(f.Value, func(v flag.Value) bool {
+			_, ok := v.(*requiredValue)
+			return ok
+		})
+		if required && !contains(names, f.Name) {
+			names = append(names, f.Name)
+		}
+	})
+	return names
+}
+
+// Reports whether names contains name.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
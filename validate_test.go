@@ -0,0 +1,102 @@
+// Copyright 2013 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Tests if all of the problems are reported at once.
+func TestValidate(t *testing.T) {
+	resetForTesting()
+
+	cont := &cmdCont{name: "command1", command: &testCmd1{}, requiredFlags: []string{"flag1", "flag2"}, args: []string{"src"}}
+	fs := cont.command.Flags(flag.NewFlagSet("command1", flag.ContinueOnError))
+	fs.Parse([]string{})
+	errs := defaultSet.validate(cont, fs, nil)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 problems, found %v", errs)
+	}
+	msg := errs.Error()
+	for _, s := range []string{"2 problems found:", "missing required flags: -flag1, -flag2", "command1 expects arguments <src>"} {
+		if !strings.Contains(msg, s) {
+			t.Errorf("error was expected to contain %q, found %q", s, msg)
+		}
+	}
+}
+
+// Tests if the required flag error is built by the custom function.
+func TestRequiredFlagErrorFunc(t *testing.T) {
+	resetForTesting()
+
+	SetRequiredFlagErrorFunc(func(cmd string, missing []string) string {
+		return fmt.Sprintf(`{"command":%q,"missing":%q}`, cmd, missing)
+	})
+	cont := &cmdCont{name: "command1", command: &testCmd1{}, requiredFlags: []string{"flag2", "flag1"}}
+	fs := cont.command.Flags(flag.NewFlagSet("command1", flag.ContinueOnError))
+	fs.Parse([]string{})
+	err := defaultSet.checkRequiredFlags(cont, fs)
+	if expected := `{"command":"command1","missing":["flag1" "flag2"]}`; err == nil || err.Error() != expected {
+		t.Errorf("expected %v, found %v", expected, err)
+	}
+}
+
+// Tests if the missing required flags are reported as a
+// MissingFlagsError.
+func TestMissingFlagsError(t *testing.T) {
+	resetForTesting("command1")
+
+	On("command1", "", &testCmd1{}, []string{"flag1", "a"})
+	var missing *MissingFlagsError
+	if err := ParseErr(); !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingFlagsError, found %v", err)
+	}
+	if missing.Command != "command1" || !reflect.DeepEqual(missing.Flags, []string{"a", "flag1"}) {
+		t.Errorf("unexpected missing flags: %+v", missing)
+	}
+	if missing.Error() != "missing required flags: -a, -flag1" {
+		t.Errorf("unexpected message %q", missing.Error())
+	}
+}
+
+// Tests if flag values are checked by their validator.
+func TestSetValidator(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	port := fs.Int("port", 0, "")
+	SetValidator(fs, "port", func(value string) error {
+		if n, err := strconv.Atoi(value); err == nil && (n < 1 || n > 65535) {
+			return errors.New("must be between 1 and 65535")
+		}
+		return nil
+	})
+	if err := fs.Parse([]string{"-port=8080"}); err != nil || *port != 8080 {
+		t.Fatalf("expected port 8080, found %v, %v", *port, err)
+	}
+	err := fs.Parse([]string{"-port=70000"})
+	if err == nil || !strings.Contains(err.Error(), "-port: must be between 1 and 65535") {
+		t.Errorf("expected the validation error, found %v", err)
+	}
+	if *port != 8080 {
+		t.Errorf("a rejected value was not expected to be set, found %v", *port)
+	}
+}